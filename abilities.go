@@ -0,0 +1,448 @@
+/*
+ * File: abilities.go
+ *
+ * Description: Handles the active abilities of each character class,
+ *              e.g. the spells of a wizard, which are used via the
+ *              ability command.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/rbisewski/go_roguelike/combat"
+)
+
+// Ability ... an active ability of a character class
+type Ability struct {
+
+	// Name shown in the ability menu and stats window.
+	Name string
+
+	// Mana spent on each use, if any.
+	Cost int
+
+	// Turns before the ability can be used again, if any.
+	Cooldown int
+
+	// Whether the player must pick an adjacent tile to use it on.
+	Directed bool
+
+	// Carries out the ability; returns whether it was actually used, so
+	// that a wasted attempt costs neither a turn nor mana.
+	use func(g *Game, p *Creature, dy, dx int) bool
+}
+
+// classAbilities ... the abilities of each class, keyed by the abilities
+// field of the class type
+var classAbilities map[string][]Ability
+
+// The abilities are filled in at startup, since using an ability can in
+// turn end up listing them again, e.g. when redrawing the stats window.
+func init() {
+	classAbilities = map[string][]Ability{
+		"warrior": {
+			{"Power Attack", 0, 8, true, powerAttack},
+			{"Second Wind", 0, 50, false, secondWind},
+		},
+		"wizard": {
+			{"Magic Missile", 4, 0, false, magicMissile},
+			{"Blink", 8, 0, false, blink},
+		},
+		"thief": {
+			{"Backstab", 0, 6, true, backstab},
+			{"Lockpick", 0, 0, true, lockpick},
+		},
+		"cleric": {
+			{"Heal", 6, 0, false, heal},
+			{"Smite", 5, 0, true, smite},
+		},
+	}
+}
+
+// Spells reach any creature in sight within this many tiles.
+const spellRange = 8
+
+// Furthest a wizard can blink, in tiles.
+const blinkRange = 6
+
+// Casters regain a point of mana every so many turns.
+const manaRegenTurns = 3
+
+// abilities ... the abilities of the class of the creature, if any.
+/*
+ * @return    Ability[]    list of abilities
+ */
+func (m *Creature) abilities() []Ability {
+
+	if m.class == nil {
+		return nil
+	}
+
+	return classAbilities[m.class.HasAbilities]
+}
+
+// maxMana ... size of the mana pool of the creature, which grows with
+// Intelligence for a wizard and Wisdom for a cleric; other classes have
+// none at all.
+/*
+ * @return    int    maximum mana
+ */
+func (m *Creature) maxMana() int {
+
+	if m.class == nil {
+		return 0
+	}
+
+	switch m.class.HasAbilities {
+	case "wizard":
+		return 2 * int(m.Intelligence)
+	case "cleric":
+		return 2 * int(m.Wisdom)
+	}
+
+	return 0
+}
+
+// abilityStatus ... short description of whether an ability is ready, as
+// shown in the ability menu and stats window.
+/*
+ * @param     Ability    the ability
+ *
+ * @return    string     e.g. "ready", "4 MP" or "3 turns"
+ */
+func (m *Creature) abilityStatus(ab Ability) string {
+
+	if turns := m.cooldowns[ab.Name]; turns > 0 {
+		return fmt.Sprintf("%d turns", turns)
+	}
+
+	if ab.Cost > 0 {
+		return fmt.Sprintf("%d MP", ab.Cost)
+	}
+
+	return "ready"
+}
+
+// abilityBlocked ... why an ability cannot be used right now, if it
+// cannot.
+/*
+ * @param     Ability    the ability
+ *
+ * @return    string     reason the ability cannot be used, or ""
+ */
+func (m *Creature) abilityBlocked(ab Ability) string {
+
+	if turns := m.cooldowns[ab.Name]; turns > 0 {
+		return fmt.Sprintf("%s will be ready again in %d turns.", ab.Name,
+			turns)
+	}
+
+	if m.Mana < ab.Cost {
+		return fmt.Sprintf("You do not have enough mana for %s.", ab.Name)
+	}
+
+	return ""
+}
+
+// tickAbilities ... count down the cooldowns of the creature and restore
+// some of its mana, once per turn.
+/*
+ * @param     int    number of the current turn
+ *
+ * @return    none
+ */
+func (m *Creature) tickAbilities(turn int) {
+
+	for name, turns := range m.cooldowns {
+		if turns > 1 {
+			m.cooldowns[name] = turns - 1
+			continue
+		}
+
+		delete(m.cooldowns, name)
+		if m.species == "player" {
+			MessageLog.log(fmt.Sprintf("%s is ready again.", name))
+		}
+	}
+
+	if turn%manaRegenTurns == 0 && m.Mana < m.maxMana() {
+		m.Mana++
+	}
+}
+
+// UseAbility ... ask the player which ability to use, and use it; this
+// takes time, unless the ability could not be used.
+/*
+ * @return    none
+ */
+func (g *Game) UseAbility() {
+
+	p := g.Player
+
+	abilities := p.abilities()
+	if len(abilities) == 0 {
+		MessageLog.log("You have no special abilities.")
+		return
+	}
+
+	// A stunned player cannot so much as pick an ability.
+	if p.stunned() {
+		g.advanceTime()
+		return
+	}
+
+	ab, ok := ChooseAbility(p, abilities)
+	if !ok {
+		return
+	}
+
+	if reason := p.abilityBlocked(ab); reason != "" {
+		MessageLog.log(reason)
+		return
+	}
+
+	var dy, dx int
+	if ab.Directed {
+		if dy, dx, ok = ChooseDirection(); !ok {
+			return
+		}
+	}
+
+	if !ab.use(g, p, dy, dx) {
+		return
+	}
+
+	p.Mana -= ab.Cost
+	p.spend(actionAbility)
+	g.advanceTime()
+
+	// The cooldown starts once the turn is over, so that it lasts the
+	// full number of turns.
+	if ab.Cooldown > 0 {
+		if p.cooldowns == nil {
+			p.cooldowns = make(map[string]int)
+		}
+		p.cooldowns[ab.Name] = ab.Cooldown
+	}
+}
+
+// adjacentTarget ... the creature on a tile next to the player, if any.
+/*
+ * @param     Creature*    pointer to the player
+ * @param     int          y-direction
+ * @param     int          x-direction
+ *
+ * @return    Creature*    creature on that tile, or nil
+ */
+func adjacentTarget(p *Creature, dy, dx int) *Creature {
+
+	_, _, target, _ := p.area.GetTileInfo(p.Y+dy, p.X+dx)
+	if target == nil || target == p {
+		MessageLog.log("There is nothing there.")
+		return nil
+	}
+
+	return target
+}
+
+// nearestVisibleTarget ... the closest creature in sight of the player,
+// within range of a spell; a peaceful shopkeeper is never picked, since a
+// stray spell would turn them hostile.
+/*
+ * @param     Creature*    pointer to the player
+ *
+ * @return    Creature*    the closest creature, or nil if none
+ */
+func nearestVisibleTarget(p *Creature) *Creature {
+
+	var nearest *Creature
+	best := spellRange*spellRange + 1
+
+	for _, m := range p.area.Creatures {
+
+		if m == p || m.Hp < 1 || m.isShopkeeper() ||
+			!p.area.IsVisible(m.Y, m.X) {
+			continue
+		}
+
+		if d := distanceSquared(m.Y-p.Y, m.X-p.X); d < best {
+			nearest, best = m, d
+		}
+	}
+
+	if nearest == nil {
+		MessageLog.log("There is nothing in sight.")
+	}
+
+	return nearest
+}
+
+// restoreHp ... heal the player, but not past their maximum hit points.
+/*
+ * @param     Creature*    pointer to the player
+ * @param     int          hit points to restore
+ *
+ * @return    int          hit points actually restored
+ */
+func restoreHp(p *Creature, amount int) int {
+
+	if p.Hp+amount > p.MaxHp {
+		amount = p.MaxHp - p.Hp
+	}
+	p.Hp += amount
+
+	return amount
+}
+
+// powerAttack ... warrior; a blow with the full strength of the warrior
+// behind it, adding half their Strength to the attack.
+func powerAttack(g *Game, p *Creature, dy, dx int) bool {
+
+	target := adjacentTarget(p, dy, dx)
+	if target == nil {
+		return false
+	}
+
+	attacker := p.combatant()
+	attacker.Attack += int(p.Strength) / 2
+
+	MessageLog.log("You put all of your strength into the blow.")
+	p.meleeStrike(target, combat.Resolve(attacker, target.combatant(),
+		g.rng.Combat))
+
+	return true
+}
+
+// secondWind ... warrior; recover a quarter of maximum hit points.
+func secondWind(g *Game, p *Creature, dy, dx int) bool {
+
+	if p.Hp >= p.MaxHp {
+		MessageLog.log("You are not hurt.")
+		return false
+	}
+
+	amount := p.MaxHp / 4
+	if amount < 1 {
+		amount = 1
+	}
+
+	MessageLog.log(fmt.Sprintf("You catch your breath, and recover %d hit "+
+		"points.", restoreHp(p, amount)))
+
+	return true
+}
+
+// magicMissile ... wizard; a bolt that never misses the nearest creature
+// in sight, and does more damage the greater the Intelligence.
+func magicMissile(g *Game, p *Creature, dy, dx int) bool {
+
+	target := nearestVisibleTarget(p)
+	if target == nil {
+		return false
+	}
+
+	damage := int(p.Intelligence)/2 + g.rng.Combat.Intn(4) + 1
+
+	MessageLog.log(fmt.Sprintf("A magic missile streaks toward the %s.",
+		target.name))
+	p.strike(target, combat.Result{Outcome: combat.Hit, Damage: damage})
+
+	return true
+}
+
+// blink ... wizard; teleport to a random open tile in sight.
+func blink(g *Game, p *Creature, dy, dx int) bool {
+
+	a := p.area
+	spots := make([]Coords, 0)
+
+	for y := p.Y - blinkRange; y <= p.Y+blinkRange; y++ {
+		for x := p.X - blinkRange; x <= p.X+blinkRange; x++ {
+
+			if y < 0 || x < 0 || y >= a.Height || x >= a.Width ||
+				chebyshev(y-p.Y, x-p.X) < 2 || !a.IsVisible(y, x) {
+				continue
+			}
+
+			_, blocks, hasCreature, _ := a.GetTileInfo(y, x)
+			if !blocks && hasCreature == nil {
+				spots = append(spots, Coords{y: y, x: x})
+			}
+		}
+	}
+
+	if len(spots) == 0 {
+		MessageLog.log("There is nowhere to blink to.")
+		return false
+	}
+
+	spot := spots[g.rng.Combat.Intn(len(spots))]
+	p.Y, p.X = spot.y, spot.x
+	MessageLog.log("You blink out of sight, and reappear nearby.")
+
+	return true
+}
+
+// backstab ... thief; a creature that has not yet been hurt is caught
+// unawares for triple damage, otherwise this is an ordinary attack.
+func backstab(g *Game, p *Creature, dy, dx int) bool {
+
+	target := adjacentTarget(p, dy, dx)
+	if target == nil {
+		return false
+	}
+
+	attacker, defender := p.combatant(), target.combatant()
+
+	if target.Hp < target.MaxHp {
+		MessageLog.log(fmt.Sprintf("The %s is on guard.", target.name))
+		p.meleeStrike(target, combat.Resolve(attacker, defender,
+			g.rng.Combat))
+		return true
+	}
+
+	damage := combat.Damage(attacker, defender, combat.Hit)
+
+	MessageLog.log(fmt.Sprintf("You catch the %s unawares!", target.name))
+	p.meleeStrike(target, combat.Result{Outcome: combat.Critical,
+		Damage: 3 * damage})
+
+	return true
+}
+
+// heal ... cleric; a prayer that restores more hit points the greater the
+// Wisdom.
+func heal(g *Game, p *Creature, dy, dx int) bool {
+
+	if p.Hp >= p.MaxHp {
+		MessageLog.log("You are not hurt.")
+		return false
+	}
+
+	amount := int(p.Wisdom)/2 + g.rng.Combat.Intn(6) + 1
+
+	MessageLog.log(fmt.Sprintf("You pray, and recover %d hit points.",
+		restoreHp(p, amount)))
+
+	return true
+}
+
+// smite ... cleric; divine wrath that never misses an adjacent creature,
+// and does more damage the greater the Wisdom.
+func smite(g *Game, p *Creature, dy, dx int) bool {
+
+	target := adjacentTarget(p, dy, dx)
+	if target == nil {
+		return false
+	}
+
+	damage := int(p.Wisdom)/2 + g.rng.Combat.Intn(4) + 1
+
+	MessageLog.log(fmt.Sprintf("You call down divine wrath upon the %s.",
+		target.name))
+	p.strike(target, combat.Result{Outcome: combat.Hit, Damage: damage})
+
+	return true
+}
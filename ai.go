@@ -9,102 +9,480 @@ package main
 import (
 	"fmt"
 	"math"
+
+	"github.com/rbisewski/go_roguelike/pathfind"
+	"github.com/rbisewski/go_roguelike/types"
 )
 
-//! Routine to handle the monster AI actions.
+// chaseDistance ... distance at which most monsters notice the player
+const chaseDistance = 6
+
+// packHuntDistance ... distance at which a pack hunter notices the player,
+// so long as some of its pack are close by
+const packHuntDistance = 10
+
+// packRadius ... how close another member of the species must be to count
+// as part of the pack
+const packRadius = 8
+
+// territoryRadius ... how far a territorial monster strays from home
+const territoryRadius = 5
+
+// fleePercent ... percentage of health below which a cowardly monster flees
+const fleePercent = 30
+
+// chaseSearchLimit ... maximum number of tiles a monster will consider
+// when searching for a path to the player; this keeps the cost of a turn
+// low even with hundreds of creatures on the level.
+const chaseSearchLimit = 400
+
+// areaGrid ... view of an area used for pathfinding, where walls and
+// tiles holding a living creature cannot be walked thru.
+type areaGrid struct {
+	area     *Area
+	occupied []bool
+}
+
+// newAreaGrid ... areaGrid constructor.
 /*
- * @params     Game*    pointer to the game instance
+ * @param     Area*        area to search
  *
- * @returns    none
+ * @return    areaGrid*    pointer to the new grid
  */
-func (g *Game) processAI() {
+func newAreaGrid(a *Area) *areaGrid {
 
-	var dy, dx int
+	grid := &areaGrid{a, make([]bool, len(a.Tiles))}
 
-	// Cycle thru all of the creatures present in the area level.
-	for _, m := range g.Area.Creatures {
-
-		// The player has no need for AI as the human controls it.
-		if m == g.Player {
-			continue
+	for _, m := range a.Creatures {
+		if m.Hp > 0 {
+			grid.occupied[m.X+m.Y*a.Width] = true
 		}
+	}
 
-		// Figure out the difference between the player coords and
-		// given monster; if they are zero do nothing...
-		ydist := g.Player.Y - m.Y
-		xdist := g.Player.X - m.X
-		if ydist == 0 && xdist == 0 {
-			continue
-		}
+	return grid
+}
 
-		// Set the current viewing distance.
-		distance := math.Sqrt(float64(xdist*xdist + ydist*ydist))
-		if distance == 0 {
-			continue
-		}
+// Size ... height and width of the area
+func (grid *areaGrid) Size() (int, int) {
+	return grid.area.Height, grid.area.Width
+}
 
-		// If the distance is too big between the player and the creature,
-		// have the monster do nothing half of the time.
-		if distance > 6 && TossCoin() {
-			continue
-		}
+// Passable ... whether a creature could step onto the tile at (x,y)
+func (grid *areaGrid) Passable(y, x int) bool {
+	i := x + y*grid.area.Width
+	return !grid.area.Tiles[i].BlockMove && !grid.occupied[i]
+}
 
-		// The other half of the time, pick a random location nearby from
-		// one of the 8 cells nearby to move the creature.
-		if distance > 6 {
-
-			// Variable declaration.
-			dx := 0
-			dy := 0
-
-			// Pick a number between 0-8 and add 1, so as to make it similar
-			// in concept to a QWERTY keypad setup:
-			//
-			// 7 8 9
-			// 4 5 6
-			// 1 2 3
-			//
-			// Where 7 is equal to north-east, 2 is equal to south, and etc.
-			//
-			KeypadLocation := getRandomNumBetweenZeroAndMax(8) + 1
-
-			// Adjust the X if the creature moved to the west.
-			if KeypadLocation == 3 || KeypadLocation == 6 || KeypadLocation == 9 {
-				dx = -1
-
-				// Adjust the X if the creature moved to the east.
-			} else if KeypadLocation == 7 || KeypadLocation == 4 || KeypadLocation == 1 {
-				dx = 1
-			}
+// moveCreature ... move a creature, keeping track of where it ends up.
+/*
+ * @param     Creature*    creature to move
+ * @param     int          change in y-value
+ * @param     int          change in x-value
+ *
+ * @return    none
+ */
+func (grid *areaGrid) moveCreature(m *Creature, dy, dx int) {
+
+	oldY, oldX := m.Y, m.X
+	m.Move(dy, dx)
+
+	grid.occupied[oldX+oldY*grid.area.Width] = false
+	if m.Hp > 0 {
+		grid.occupied[m.X+m.Y*grid.area.Width] = true
+	}
+}
+
+// actMonster ... have a monster take an action, according to its
+// behaviour profile.
+/*
+ * @param     areaGrid*    free tiles of the area
+ * @param     Creature*    monster taking its turn
+ *
+ * @return    none
+ */
+func (g *Game) actMonster(grid *areaGrid, m *Creature) {
+
+	// Figure out the difference between the player coords and
+	// given monster; if they are zero do nothing...
+	ydist := g.Player.Y - m.Y
+	xdist := g.Player.X - m.X
+	if ydist == 0 && xdist == 0 {
+		return
+	}
+
+	// Set the current viewing distance.
+	distance := math.Sqrt(float64(xdist*xdist + ydist*ydist))
+	if distance == 0 {
+		return
+	}
+
+	// Blind monsters only notice the player when right next to them.
+	if m.hasStatus(types.StatusBlindness) && distance >= 2 {
+		distance = math.Inf(1)
+	}
+
+	// Act according to the behaviour profile of the creature type.
+	switch m.behaviour {
+	case types.BehaviourCowardly:
+		g.actCowardly(grid, m, distance)
+	case types.BehaviourTerritorial:
+		g.actTerritorial(grid, m)
+	case types.BehaviourWanderer:
+		g.actWanderer(grid, m)
+	case types.BehaviourPackHunter:
+		g.actPackHunter(grid, m, distance)
+	case types.BehaviourAmbusher:
+		g.actAmbusher(grid, m)
+	case types.BehaviourShopkeeper:
+		// Shopkeepers mind their shop, and never leave it.
+	default:
+		g.actAggressive(grid, m, distance)
+	}
+}
 
-			// Adjust the Y if the creature moved to the north.
-			if KeypadLocation == 7 || KeypadLocation == 8 || KeypadLocation == 9 {
-				dy = -1
+// actAggressive ... chase the player when nearby, otherwise wander about
+// half of the time.
+/*
+ * @param     areaGrid*    free tiles of the area
+ * @param     Creature*    monster taking its turn
+ * @param     float64      distance between the monster and the player
+ *
+ * @return    none
+ */
+func (g *Game) actAggressive(grid *areaGrid, m *Creature, distance float64) {
+
+	if distance <= chaseDistance {
+		g.chase(grid, m)
+		return
+	}
+
+	// If the distance is too big between the player and the creature,
+	// have the monster do nothing half of the time.
+	if TossCoin(g.rng.AI) {
+		return
+	}
+
+	g.wander(grid, m)
+}
+
+// actCowardly ... behave aggressively, until badly hurt, and then run
+// away from the player.
+/*
+ * @param     areaGrid*    free tiles of the area
+ * @param     Creature*    monster taking its turn
+ * @param     float64      distance between the monster and the player
+ *
+ * @return    none
+ */
+func (g *Game) actCowardly(grid *areaGrid, m *Creature, distance float64) {
+
+	if m.Hp*100 >= m.MaxHp*fleePercent || distance > chaseDistance {
+		g.actAggressive(grid, m, distance)
+		return
+	}
+
+	// If cornered, the monster has no choice but to fight.
+	if !g.flee(grid, m) {
+		g.chase(grid, m)
+	}
+}
+
+// actTerritorial ... guard the area around where the monster was first
+// spawned, chasing the player only while they are within it.
+/*
+ * @param     areaGrid*    free tiles of the area
+ * @param     Creature*    monster taking its turn
+ *
+ * @return    none
+ */
+func (g *Game) actTerritorial(grid *areaGrid, m *Creature) {
+
+	// Intruders are chased away, or to their death.
+	if chebyshev(g.Player.Y-m.homeY, g.Player.X-m.homeX) <= territoryRadius {
+		g.chase(grid, m)
+		return
+	}
+
+	// Head back home if the chase led the monster astray...
+	if chebyshev(m.Y-m.homeY, m.X-m.homeX) > territoryRadius {
+		g.stepToward(grid, m, m.homeY, m.homeX)
+		return
+	}
+
+	// ...otherwise pace about the territory now and then.
+	if TossCoin(g.rng.AI) {
+		return
+	}
+
+	g.wander(grid, m)
+}
+
+// actWanderer ... roam about the level at random, paying no heed to the
+// player unless they are right next to the monster.
+/*
+ * @param     areaGrid*    free tiles of the area
+ * @param     Creature*    monster taking its turn
+ *
+ * @return    none
+ */
+func (g *Game) actWanderer(grid *areaGrid, m *Creature) {
+
+	if chebyshev(g.Player.Y-m.Y, g.Player.X-m.X) <= 1 {
+		g.chase(grid, m)
+		return
+	}
+
+	g.wander(grid, m)
+}
+
+// actPackHunter ... hunt the player from further away when the rest of
+// the pack is close by; a lone hunter instead tries to regroup.
+/*
+ * @param     areaGrid*    free tiles of the area
+ * @param     Creature*    monster taking its turn
+ * @param     float64      distance between the monster and the player
+ *
+ * @return    none
+ */
+func (g *Game) actPackHunter(grid *areaGrid, m *Creature, distance float64) {
 
-				// Adjust the Y if the creature moved to the south.
-			} else if KeypadLocation == 1 || KeypadLocation == 2 || KeypadLocation == 3 {
-				dy = 1
+	if distance > packHuntDistance {
+		g.actAggressive(grid, m, distance)
+		return
+	}
+
+	packmates, nearest := g.Area.packOf(m)
+
+	// Together the pack is bold enough to close in on the player.
+	if packmates > 0 || chebyshev(g.Player.Y-m.Y, g.Player.X-m.X) <= 1 {
+		g.chase(grid, m)
+		return
+	}
+
+	// Alone it keeps its distance, and heads for the nearest of its kind.
+	if nearest != nil && g.stepToward(grid, m, nearest.Y, nearest.X) {
+		return
+	}
+
+	// A hunter with no pack at all fends for itself.
+	g.actAggressive(grid, m, distance)
+}
+
+// actAmbusher ... lie in wait until the player is right next to the
+// monster, and then attack.
+/*
+ * @param     areaGrid*    free tiles of the area
+ * @param     Creature*    monster taking its turn
+ *
+ * @return    none
+ */
+func (g *Game) actAmbusher(grid *areaGrid, m *Creature) {
+
+	if chebyshev(g.Player.Y-m.Y, g.Player.X-m.X) <= 1 {
+		g.chase(grid, m)
+	}
+}
+
+// chase ... take a step towards the player, attacking them if adjacent.
+/*
+ * @param     areaGrid*    free tiles of the area
+ * @param     Creature*    monster taking its turn
+ *
+ * @return    none
+ */
+func (g *Game) chase(grid *areaGrid, m *Creature) {
+
+	// Chase the player around any walls or creatures in the way.
+	if g.stepToward(grid, m, g.Player.Y, g.Player.X) {
+		return
+	}
+
+	// Otherwise there is no way thru, so simply head straight for
+	// the player.
+	ydist := g.Player.Y - m.Y
+	xdist := g.Player.X - m.X
+	distance := math.Sqrt(float64(xdist*xdist + ydist*ydist))
+
+	dx := Round(float64(int(xdist) / Round(distance)))
+	dy := Round(float64(int(ydist) / Round(distance)))
+
+	DebugLog(g, fmt.Sprintf("dx, dy, dist = %d, %d, %g->%d | xdist: %d - ydist: %d    ",
+		dx,
+		dy,
+		distance,
+		Round(distance),
+		xdist,
+		ydist))
+
+	// Tell the monster to move to the determined location.
+	grid.moveCreature(m, dy, dx)
+}
+
+// stepToward ... take the first step along the shortest path to (x,y).
+/*
+ * @param     areaGrid*    free tiles of the area
+ * @param     Creature*    monster taking its turn
+ * @param     int          y-value of the destination
+ * @param     int          x-value of the destination
+ *
+ * @return    bool         whether or not a path was found
+ */
+func (g *Game) stepToward(grid *areaGrid, m *Creature, y, x int) bool {
+
+	path := pathfind.Find(grid, pathfind.Point{Y: m.Y, X: m.X},
+		pathfind.Point{Y: y, X: x}, chaseSearchLimit)
+	if len(path) == 0 {
+		return false
+	}
+
+	// The destination may be held by a creature other than the player,
+	// in which case the monster waits rather than attack it.
+	step := path[0]
+	if grid.occupied[step.X+step.Y*grid.area.Width] &&
+		(step.Y != g.Player.Y || step.X != g.Player.X) {
+		return true
+	}
+
+	grid.moveCreature(m, step.Y-m.Y, step.X-m.X)
+
+	return true
+}
+
+// flee ... step to whichever neighbouring tile is furthest from the player.
+/*
+ * @param     areaGrid*    free tiles of the area
+ * @param     Creature*    monster taking its turn
+ *
+ * @return    bool         whether or not the monster could get further away
+ */
+func (g *Game) flee(grid *areaGrid, m *Creature) bool {
+
+	bestY, bestX := 0, 0
+	best := distanceSquared(g.Player.Y-m.Y, g.Player.X-m.X)
+
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+
+			y, x := m.Y+dy, m.X+dx
+			if y < 0 || y >= grid.area.Height || x < 0 || x >= grid.area.Width {
+				continue
+			}
+			if !grid.Passable(y, x) {
+				continue
 			}
 
-			// Attempt to move the creature to that location.
-			m.Move(dy, dx)
+			if d := distanceSquared(g.Player.Y-y, g.Player.X-x); d > best {
+				best, bestY, bestX = d, dy, dx
+			}
+		}
+	}
+
+	if bestY == 0 && bestX == 0 {
+		return false
+	}
+
+	grid.moveCreature(m, bestY, bestX)
+
+	return true
+}
+
+// wander ... move the monster to a random neighbouring tile.
+/*
+ * @param     areaGrid*    free tiles of the area
+ * @param     Creature*    monster taking its turn
+ *
+ * @return    none
+ */
+func (g *Game) wander(grid *areaGrid, m *Creature) {
+
+	// Variable declaration.
+	dx := 0
+	dy := 0
+
+	// Pick a number between 0-8 and add 1, so as to make it similar
+	// in concept to a QWERTY keypad setup:
+	//
+	// 7 8 9
+	// 4 5 6
+	// 1 2 3
+	//
+	// Where 7 is equal to north-east, 2 is equal to south, and etc.
+	//
+	KeypadLocation := getRandomNumBetweenZeroAndMax(g.rng.AI, 8) + 1
+
+	// Adjust the X if the creature moved to the west.
+	if KeypadLocation == 3 || KeypadLocation == 6 || KeypadLocation == 9 {
+		dx = -1
+
+		// Adjust the X if the creature moved to the east.
+	} else if KeypadLocation == 7 || KeypadLocation == 4 || KeypadLocation == 1 {
+		dx = 1
+	}
+
+	// Adjust the Y if the creature moved to the north.
+	if KeypadLocation == 7 || KeypadLocation == 8 || KeypadLocation == 9 {
+		dy = -1
 
-			// This creature's movement action is now completed.
+		// Adjust the Y if the creature moved to the south.
+	} else if KeypadLocation == 1 || KeypadLocation == 2 || KeypadLocation == 3 {
+		dy = 1
+	}
+
+	// Attempt to move the creature to that location.
+	grid.moveCreature(m, dy, dx)
+}
+
+// packOf ... count the other living members of a creature's species that
+// are close enough to hunt alongside it.
+/*
+ * @param     Creature*    pack hunter
+ *
+ * @return    int          number of packmates within packRadius
+ *            Creature*    nearest member of the species, if any
+ */
+func (a *Area) packOf(m *Creature) (int, *Creature) {
+
+	packmates := 0
+	var nearest *Creature
+	nearestDistance := 0
+
+	for _, other := range a.Creatures {
+
+		if other == m || other.Hp <= 0 || other.species != m.species {
 			continue
 		}
 
-		dx = Round(float64(int(xdist) / Round(distance)))
-		dy = Round(float64(int(ydist) / Round(distance)))
+		d := chebyshev(other.Y-m.Y, other.X-m.X)
+		if d <= packRadius {
+			packmates++
+		}
+		if nearest == nil || d < nearestDistance {
+			nearest, nearestDistance = other, d
+		}
+	}
+
+	return packmates, nearest
+}
 
-		DebugLog(g, fmt.Sprintf("dx, dy, dist = %d, %d, %g->%d | xdist: %d - ydist: %d    ",
-			dx,
-			dy,
-			distance,
-			Round(distance),
-			xdist,
-			ydist))
+// chebyshev ... number of moves between two tiles, given a difference in
+// their coords, since diagonal moves cost the same as straight ones.
+func chebyshev(dy, dx int) int {
 
-		// Tell the monster to move to the determined location.
-		m.Move(dy, dx)
+	if dy < 0 {
+		dy = -dy
 	}
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy > dx {
+		return dy
+	}
+
+	return dx
+}
+
+// distanceSquared ... squared straight-line distance, given a difference
+// in coords.
+func distanceSquared(dy, dx int) int {
+	return dy*dy + dx*dx
 }
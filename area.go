@@ -48,81 +48,59 @@ type Area struct {
 
 	// Whether or not the current area has been populated already.
 	IsPopulatedWithCreatures bool
+
+	// Depth of this area within the dungeon, starting at 1.
+	Depth int
+
+	// Location of the up and down staircases.
+	UpY   int
+	UpX   int
+	DownY int
+	DownX int
+
+	// Tiles the player has seen at least once; remembered between visits
+	// and kept in the save file.
+	Explored []bool
+
+	// Tiles the player can currently see, as of the last FOV calculation.
+	visible []bool
+
+	// Shop on the level, if there is one.
+	Shop *Shop
+
+	// Rooms or caverns of the level, as laid out by its map generator;
+	// only needed while populating it, so not kept in the save file.
+	Regions []Region
 }
 
 // NewArea ... Generates an area and assigns a start location to the PC
 /*
  * @param    int               height
  * @param    int               width
+ * @param    MapGenerator      generator to lay out the area with
+ * @param    rand.Rand*        map generation random number stream
  *
  * @returns  Area* and (x,y)   A generated area array and (x,y) starting
  *                             points.
  */
-func NewArea(h, w int) (*Area, int, int) {
+func NewArea(h, w int, gen MapGenerator, r *rand.Rand) (*Area, int, int) {
 
-	if h < 1 || w < 1 {
+	if h < 1 || w < 1 || gen == nil || r == nil {
 		DebugLog(&G, fmt.Sprintf("NewArea() --> invalid input"))
 		return nil, 0, 0
 	}
 
-	var ry, rx int
-	var creatures []*Creature
-	var items []*Item
-
-	// Real x,y
-	ry, rx = 0, 0
-
-	creatures = make([]*Creature, 0)
-	items = make([]*Item, 0)
-
-	// Number of iterations to use
-	nIts := 4
-
-	// Setup an iterator
-	t := make([][]Tile, nIts)
+	creatures := make([]*Creature, 0)
+	items := make([]*Item, 0)
 
 	// Set the area padding
 	SetPad(h, w)
 
-	for it := 0; it < nIts; it++ {
-
-		// Assign a tile to that given location
-		t[it] = make([]Tile, w*h)
-
-		// For all of the y-coords (height)...
-		for y := 0; y < h; y++ {
-
-			// For all of the x-coords (width)...
-			for x := 0; x < w; x++ {
-
-				// On first iteration, place random tiles.
-				if it == 0 {
-					t[it][x+y*w] = placeRandomTile()
-					continue
-				}
-
-				// Otherwise check for wall placement.
-				if mapBorders(y, x) || adjacentWalls(y, x, w, t[it-1]) >= 4 {
-					t[it][x+y*w] = Tile{'#', true, true}
-					continue
-				}
-
-				// Or draw ground.
-				t[it][x+y*w] = Tile{'.', false, false}
-
-				// If we are at last Iteration
-				if it == nIts-1 {
-
-					//set the spawn coords
-					ry = y
-					rx = x
-				}
-			}
-		}
-	}
+	tiles, regions, ry, rx := gen.Generate(h, w, r)
 
 	// Return the completed area-object plus start coords.
-	return &Area{t[nIts-1], creatures, items, h, w, false}, ry, rx
+	return &Area{tiles, creatures, items, h, w, false, 1, ry, rx, ry, rx,
+		make([]bool, w*h), make([]bool, w*h), nil, regions}, ry, rx
 }
 
 // GetTileInfo ... Grab info about a given tile, specific what it is,
@@ -194,12 +172,14 @@ func (a *Area) GetTileInfo(y, x int) (ch rune,
 
 // placeRandomTile ... randomly return a tile (e.g. # == wall and . == ground)
 /*
- * @return    Tile    newly initialized tile object
+ * @param     rand.Rand*   map generation random number stream
+ *
+ * @return    Tile         newly initialized tile object
  */
-func placeRandomTile() Tile {
+func placeRandomTile(r *rand.Rand) Tile {
 
 	// Make about 30% of the tiles walls (i.e. --> #)
-	if rand.Intn(100) <= 30 {
+	if r.Intn(100) <= 30 {
 		return Tile{'#', true, true}
 	}
 
@@ -208,12 +188,13 @@ func placeRandomTile() Tile {
 
 // selectRandomTile ... Returns a random set of coordinates.
 /*
- * @param      int       height
- * @param      int       width
+ * @param      rand.Rand*   random number stream
+ * @param      int          height
+ * @param      int          width
  *
- * @returns    points    an (x,y) coord
+ * @returns    points       an (x,y) coord
  */
-func selectRandomTile(h, w int) (int, int) {
+func selectRandomTile(r *rand.Rand, h, w int) (int, int) {
 
 	if h < 1 || w < 1 {
 		DebugLog(&G, fmt.Sprintf("selectRandomTile() --> invalid input"))
@@ -221,8 +202,8 @@ func selectRandomTile(h, w int) (int, int) {
 	}
 
 	// Randomly generate a y-value and an x-value
-	y := rand.Intn(h)
-	x := rand.Intn(w)
+	y := r.Intn(h)
+	x := r.Intn(w)
 
 	return y, x
 }
@@ -230,6 +211,7 @@ func selectRandomTile(h, w int) (int, int) {
 // explodeTile ... With the tile given as argument make some new tiles
 // randomly around it.
 /*
+ * @param     rand.Rand* map generation random number stream
  * @param     int        y-coord
  * @param     int        x-coord
  * @param     int        w-coord
@@ -237,7 +219,7 @@ func selectRandomTile(h, w int) (int, int) {
  *
  * @return    none
  */
-func explodeTile(y, x, w int, t *[]Tile) {
+func explodeTile(r *rand.Rand, y, x, w int, t *[]Tile) {
 
 	if t == nil {
 		DebugLog(&G, fmt.Sprintf("explodeTile() --> invalid input"))
@@ -250,16 +232,16 @@ func explodeTile(y, x, w int, t *[]Tile) {
 	for it := 0; it < 5; it++ {
 
 		// Randomly generate some small integers.
-		ry := rand.Intn(2)
-		rx := rand.Intn(2)
+		ry := r.Intn(2)
+		rx := r.Intn(2)
 
 		// If heads then go back 1 for y-coord.
-		if TossCoin() {
+		if TossCoin(r) {
 			ry *= -1
 		}
 
 		// If heads then go back 1 for x-coord.
-		if TossCoin() {
+		if TossCoin(r) {
 			rx *= -1
 		}
 
@@ -527,12 +509,20 @@ func mapBorders(y, x int) bool {
 	return y == 0 || y == WorldHeight-1 || x == 0 || x == WorldWidth-1
 }
 
+// tilesPerMonster ... fewest tiles of open floor for every monster placed
+const tilesPerMonster = 60
+
+// tilesPerItem ... fewest tiles of open floor for every item placed
+const tilesPerItem = 250
+
 //! Populate an area with creatures / critters / monsters; this only works
 //! if the level has yet to be populated.
 /*
- * @returns    bool    whether or not the tile is "blocking"
+ * @param      rand.Rand*   spawning random number stream
+ *
+ * @returns    bool         whether or not the tile is "blocking"
  */
-func (a *Area) populateAreaWithCreatures() bool {
+func (a *Area) populateAreaWithCreatures(r *rand.Rand) bool {
 
 	if a == nil {
 		DebugLog(&G, fmt.Sprintf("populateAreaWithCreatures() --> "+
@@ -564,17 +554,26 @@ func (a *Area) populateAreaWithCreatures() bool {
 		MaxNumberOfMonsters = 0
 	}
 
-	// Continue to add monsters until the max has been reached.
-	for i = 0; i < MaxNumberOfMonsters; i++ {
+	// Levels of rooms and corridors have far less open floor than those
+	// of caverns, so cap it by the size of the rooms or caverns.
+	if open := a.regionTileCount(); open > 0 {
+		MaxNumberOfMonsters = uint(Min(int(MaxNumberOfMonsters),
+			open/tilesPerMonster))
+	}
+
+	// Continue to add monsters until the max has been reached, giving up
+	// after a while in case the level is mostly walls.
+	attempts := uint(0)
+	for i = 0; i < MaxNumberOfMonsters &&
+		attempts < MaxNumberOfMonsters*20; i++ {
+
+		attempts++
 
 		// Set the already utilized flag back to false.
 		CoordIsAlreadyUtilized = false
 
-		// Grab a random x coord value.
-		dx := getRandomNumBetweenZeroAndMax(a.Width)
-
-		// Grab a random y coord value.
-		dy := getRandomNumBetweenZeroAndMax(a.Height)
+		// Grab a random spot within the rooms or caverns of the level.
+		dy, dx := a.randomSpawnTile(r)
 
 		// Assemble a Coords object from the above info.
 		CurrentCoordPair := Coords{strconv.Itoa(dx) + ":" + strconv.Itoa(dy),
@@ -614,8 +613,10 @@ func (a *Area) populateAreaWithCreatures() bool {
 		// about the tile at this (x,y) location.
 		tileRune, blocking, _, _ := a.GetTileInfo(dy, dx)
 
-		// Safety check, make sure the tile isn't a wall or blocking tile.
-		if tileRune == '#' || blocking {
+		// Safety check, make sure the tile isn't a wall or blocking tile,
+		// a staircase, or inside a shop.
+		if tileRune == '#' || blocking || a.inShop(dy, dx) ||
+			(dy == a.UpY && dx == a.UpX) || (dy == a.DownY && dx == a.DownX) {
 
 			// If it is a wall tile, decrement the value of i
 			if i > 0 {
@@ -656,26 +657,18 @@ func (a *Area) populateAreaWithCreatures() bool {
 		//
 		if GlobalCreatureTypeInfoMapIsPopulated {
 
-			// Determine the current number of types
-			numOfTypes := len(GlobalCreatureTypeInfoMap)
+			// Grab the creature types in sorted order, since the order of
+			// a map is random and would make the seed meaningless.
+			typeNames := spawnableCreatureTypeNames()
 
 			// Attempt to grab a number between 0 and numOfTypes
-			chosenTypeNum := getRandomNumBetweenZeroAndMax(numOfTypes - 1)
+			chosenTypeNum := getRandomNumBetweenZeroAndMax(r, len(typeNames))
 
 			// Grab a creature type stored at the address specified by
 			// chosenTypeNum.
 			var chosenCreatureType string
-			var i int
-			for k := range GlobalCreatureTypeInfoMap {
-
-				// If the counter matches the requested element, break
-				if i == chosenTypeNum {
-					chosenCreatureType = k
-					break
-				}
-
-				// Else increment the counter
-				i++
+			if chosenTypeNum < len(typeNames) {
+				chosenCreatureType = typeNames[chosenTypeNum]
 			}
 
 			// Safety check, ensure that the chosenCreatureType isn't empty.
@@ -684,7 +677,8 @@ func (a *Area) populateAreaWithCreatures() bool {
 			}
 
 			// Attempt to spawn a creature of that type
-			wasSuccessful := spawnCreatureToArray(chosenCreatureType, dx, dy, a)
+			wasSuccessful := spawnCreatureToArray(chosenCreatureType, dx, dy,
+				a, r)
 			if !wasSuccessful {
 				DebugLog(&G, fmt.Sprintf("populateAreaWithCreatures() --> "+
 					"Unable to spawn chosen creature into the area!"))
@@ -705,3 +699,107 @@ func (a *Area) populateAreaWithCreatures() bool {
 
 	return true
 }
+
+// populateAreaWithItems ... scatter items across the open tiles of a
+// level, picking the rarer ones less often and only those that may be
+// found at its depth.
+/*
+ * @param      rand.Rand*   spawning random number stream
+ *
+ * @returns    bool         whether or not any items were placed
+ */
+func (a *Area) populateAreaWithItems(r *rand.Rand) bool {
+
+	if a == nil || r == nil {
+		DebugLog(&G, fmt.Sprintf("populateAreaWithItems() --> "+
+			"invalid input"))
+		return false
+	}
+
+	if !GlobalItemTypeInfoMapIsPopulated {
+		return false
+	}
+
+	// There is roughly one item for every 20x20 tiles of the level.
+	numberOfItems := (a.Height / 20) * (a.Width / 20)
+
+	// Just as with monsters, levels with less open floor get fewer.
+	if open := a.regionTileCount(); open > 0 {
+		numberOfItems = Min(numberOfItems, open/tilesPerItem)
+	}
+
+	// Give up on finding an open tile after a while, in case the level
+	// is mostly walls.
+	placed := 0
+	for attempts := 0; placed < numberOfItems &&
+		attempts < numberOfItems*20; attempts++ {
+
+		y, x := a.randomSpawnTile(r)
+
+		// Items are never placed on walls, staircases, other items or in
+		// a shop, whose wares are placed along with it.
+		_, blocking, _, hasItems := a.GetTileInfo(y, x)
+		if blocking || len(hasItems) > 0 || (y == a.UpY && x == a.UpX) ||
+			(y == a.DownY && x == a.DownX) || a.inShop(y, x) {
+			continue
+		}
+
+		name := pickItemType(a.Depth, r)
+		if name == "" {
+			break
+		}
+
+		if spawnItemToArray(name, x, y, a) {
+			placed++
+		}
+	}
+
+	DebugLog(&G, fmt.Sprintf("populateAreaWithItems() --> placed %d "+
+		"items into the area", placed))
+
+	return placed > 0
+}
+
+// regionTileCount ... number of tiles in the rooms or caverns of the level.
+/*
+ * @returns    int    number of tiles, or 0 if the regions are unknown
+ */
+func (a *Area) regionTileCount() int {
+
+	total := 0
+	for _, region := range a.Regions {
+		total += len(region.Tiles)
+	}
+
+	return total
+}
+
+// randomSpawnTile ... pick a random tile to spawn something on, from the
+// rooms or caverns of the level if it has any, so that e.g. corridors
+// are left clear; larger regions are picked more often.
+/*
+ * @param      rand.Rand*   spawning random number stream
+ *
+ * @returns    int          y-value
+ *             int          x-value
+ */
+func (a *Area) randomSpawnTile(r *rand.Rand) (int, int) {
+
+	total := a.regionTileCount()
+
+	// Levels loaded from a save file have no regions, so any tile will do.
+	if total == 0 {
+		return getRandomNumBetweenZeroAndMax(r, a.Height),
+			getRandomNumBetweenZeroAndMax(r, a.Width)
+	}
+
+	n := r.Intn(total)
+	for _, region := range a.Regions {
+		if n < len(region.Tiles) {
+			return region.Tiles[n] / a.Width, region.Tiles[n] % a.Width
+		}
+		n -= len(region.Tiles)
+	}
+
+	return 0, 0
+}
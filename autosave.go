@@ -0,0 +1,117 @@
+/*
+ * File: autosave.go
+ *
+ * Description: Saves the game every so many turns, as well as when the
+ *              game is told to stop by a signal or crashes, so that a run
+ *              is never lost.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// gameLock ... held by the game loop at all times, except while it waits
+// for a key, so that a signal can only save the game between moves.
+var gameLock sync.Mutex
+
+// canSave ... whether there is a game in progress worth saving.
+/*
+ * @return    bool    whether or not the game can be saved
+ */
+func (g *Game) canSave() bool {
+	return ReplayFile == "" && g.Player != nil && g.Player.Hp > 0 &&
+		g.Dungeon != nil && !g.state.Menuing() && !g.state.Quiting()
+}
+
+// autosave ... save the game once every AutosaveTurns turns. A slow
+// action may take several turns, so the save is made once the count has
+// passed the next multiple, rather than only when it lands on it.
+/*
+ * @return    none
+ */
+func (g *Game) autosave() {
+
+	if AutosaveTurns < 1 ||
+		g.Turns/AutosaveTurns <= g.autosavedTurn/AutosaveTurns ||
+		!g.canSave() {
+		return
+	}
+
+	// Only try once for each multiple, even if the save fails.
+	g.autosavedTurn = g.Turns
+
+	if err := g.SaveToSlot(); err != nil {
+		MessageLog.log("Unable to autosave the game: " + err.Error())
+		return
+	}
+
+	DebugLog(g, fmt.Sprintf("autosave() --> saved on turn %d", g.Turns))
+}
+
+// handleSignals ... save the game and restore the terminal if the game is
+// told to stop, e.g. because the terminal was closed.
+/*
+ * @return    none
+ */
+func handleSignals() {
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGHUP)
+
+	go func() {
+
+		sig := <-signals
+
+		// Wait until the game loop is idle, so the save is consistent.
+		gameLock.Lock()
+
+		saved, err := saveBeforeExit()
+		End()
+
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "go-roguelike: received %v, but the game "+
+				"could not be saved: %v\n", sig, err)
+		case saved:
+			fmt.Fprintf(os.Stderr, "go-roguelike: received %v, the game "+
+				"was saved\n", sig)
+		default:
+			fmt.Fprintf(os.Stderr, "go-roguelike: received %v\n", sig)
+		}
+
+		os.Exit(1)
+	}()
+}
+
+// saveBeforeExit ... save the game in progress, if any, as the program is
+// about to end unexpectedly.
+/*
+ * @return    bool     whether or not the game was saved
+ *            error    error message, if any
+ */
+func saveBeforeExit() (saved bool, err error) {
+
+	if !G.canSave() {
+		return false, nil
+	}
+
+	// The game may be in a bad way after a crash, so make sure a failed
+	// save cannot crash again on top of it.
+	defer func() {
+		if r := recover(); r != nil {
+			saved, err = false, fmt.Errorf("%v", r)
+		}
+	}()
+
+	if err := G.SaveToSlot(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
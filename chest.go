@@ -0,0 +1,117 @@
+/*
+ * File: chest.go
+ *
+ * Description: Locked chests, which are scattered across the levels and
+ *              can only be opened by a thief picking the lock; inside
+ *              are items and gold.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// chestRune ... how a locked chest is drawn on the map
+const chestRune = '&'
+
+// maxChests ... most locked chests placed on a single level
+const maxChests = 3
+
+// maxChestItems ... most items found inside a single chest
+const maxChestItems = 3
+
+// placeChests ... put a few locked chests on the level, each out in the
+// open, so that no chest ever blocks the way.
+/*
+ * @param     rand.Rand*   spawning random number stream
+ *
+ * @return    int          number of chests placed
+ */
+func (a *Area) placeChests(r *rand.Rand) int {
+
+	if a == nil || r == nil {
+		DebugLog(&G, fmt.Sprintf("placeChests() --> invalid input"))
+		return 0
+	}
+
+	chests := r.Intn(maxChests + 1)
+
+	// Give up on finding a spot after a while, in case the level is
+	// mostly walls.
+	placed := 0
+	for attempts := 0; placed < chests && attempts < chests*50; attempts++ {
+
+		y, x := a.randomSpawnTile(r)
+
+		// Only tiles with nothing but floor around them will do, since
+		// then there is always a way around the chest.
+		if y < 1 || x < 1 || y >= a.Height-1 || x >= a.Width-1 ||
+			a.Tiles[x+y*a.Width].Ch != '.' ||
+			adjacentWalls(y, x, a.Width, a.Tiles) > 0 ||
+			(y == a.UpY && x == a.UpX) || a.inShop(y, x) {
+			continue
+		}
+
+		a.Tiles[x+y*a.Width] = Tile{chestRune, true, false}
+		placed++
+	}
+
+	return placed
+}
+
+// lockpickChance ... percentage chance of the creature picking a lock,
+// which grows with Agility.
+/*
+ * @return    int    chance, from 0 to 95
+ */
+func (m *Creature) lockpickChance() int {
+	return Min(40+4*int(m.Agility), 95)
+}
+
+// openChest ... open the locked chest at (x,y), spilling out its items
+// and gold onto the floor where it stood.
+/*
+ * @param     int          y-value
+ * @param     int          x-value
+ * @param     rand.Rand*   random number stream
+ *
+ * @return    none
+ */
+func (a *Area) openChest(y, x int, r *rand.Rand) {
+
+	a.Tiles[x+y*a.Width] = Tile{'.', false, false}
+
+	for i := 1 + r.Intn(maxChestItems); i > 0; i-- {
+		if name := pickItemType(a.Depth, r); name != "" {
+			spawnItemToArray(name, x, y, a)
+		}
+	}
+
+	// Deeper chests hold more gold.
+	a.Items = append(a.Items, newGoldPile(5*a.Depth+r.Intn(10*a.Depth)+1,
+		y, x, a))
+}
+
+// lockpick ... thief; pick the lock of an adjacent chest, with a better
+// chance the greater the Agility. A failed attempt still takes time.
+func lockpick(g *Game, p *Creature, dy, dx int) bool {
+
+	y, x := p.Y+dy, p.X+dx
+	if y < 0 || x < 0 || y >= p.area.Height || x >= p.area.Width ||
+		p.area.Tiles[x+y*p.area.Width].Ch != chestRune {
+		MessageLog.log("There is no lock there to pick.")
+		return false
+	}
+
+	if g.rng.Combat.Intn(100) >= p.lockpickChance() {
+		MessageLog.log("You fail to pick the lock.")
+		return true
+	}
+
+	p.area.openChest(y, x, g.rng.Combat)
+	MessageLog.log("The lock clicks, and the chest springs open.")
+
+	return true
+}
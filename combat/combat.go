@@ -0,0 +1,206 @@
+/*
+ * File: combat/combat.go
+ *
+ * Description: Resolves a single attack of one creature upon another,
+ *              deciding whether it hits and how much damage it does.
+ */
+
+package combat
+
+// Combatant ... the numbers of a creature that matter in a fight
+type Combatant struct {
+
+	// Attack of the creature, including any weapons it has equipped.
+	Attack int
+
+	// Defence of the creature, including any armour it has equipped.
+	Defence int
+
+	// Stronger creatures hit harder.
+	Strength uint
+
+	// More agile creatures hit, dodge and land critical blows more often.
+	Agility uint
+}
+
+// Outcome ... how well an attack landed
+type Outcome int
+
+const (
+	// Miss ... the attack did no harm at all
+	Miss Outcome = iota
+
+	// Glancing ... the attack barely connected, for half damage
+	Glancing
+
+	// Hit ... the attack connected, for full damage
+	Hit
+
+	// Critical ... the attack struck a weak spot, for double damage
+	Critical
+)
+
+// Chances are percentages, and are kept between these bounds so that
+// every attack has some chance of landing and of missing.
+const (
+	minHitChance  = 5
+	maxHitChance  = 95
+	baseHitChance = 75
+
+	minCritChance  = 1
+	maxCritChance  = 25
+	baseCritChance = 5
+
+	// Width of the band of rolls, just short of a miss, that only glance.
+	glancingBand = 10
+
+	// Attribute value that gives no bonus or penalty.
+	averageAttribute = 10
+)
+
+// Result ... what came of an attack
+type Result struct {
+	Outcome Outcome
+	Damage  int
+
+	// The d100 roll, and the chances it was compared against.
+	Roll       int
+	HitChance  int
+	CritChance int
+}
+
+// Roller ... source of random numbers; *rand.Rand satisfies this
+type Roller interface {
+
+	// Intn returns a number in [0,n).
+	Intn(n int) int
+}
+
+// Resolve ... roll an attack of one combatant upon another.
+/*
+ * @param     Combatant    attacker
+ * @param     Combatant    defender
+ * @param     Roller       random number source
+ *
+ * @return    Result       outcome and damage of the attack
+ */
+func Resolve(attacker, defender Combatant, r Roller) Result {
+
+	hit := HitChance(attacker, defender)
+	crit := CritChance(attacker, defender)
+
+	roll := r.Intn(100)
+	outcome := OutcomeOf(roll, hit, crit)
+
+	return Result{outcome, Damage(attacker, defender, outcome), roll, hit,
+		crit}
+}
+
+// OutcomeOf ... work out how well an attack landed from a d100 roll; low
+// rolls are best.
+/*
+ * @param     int        roll, from 0 to 99
+ * @param     int        chance to hit, as a percentage
+ * @param     int        chance of a critical hit, as a percentage
+ *
+ * @return    Outcome    how well the attack landed
+ */
+func OutcomeOf(roll, hitChance, critChance int) Outcome {
+
+	switch {
+	case roll < critChance:
+		return Critical
+	case roll < hitChance-glancingBand:
+		return Hit
+	case roll < hitChance:
+		return Glancing
+	}
+
+	return Miss
+}
+
+// HitChance ... percentage chance of an attack landing at all; every
+// point of Agility the attacker has over the defender adds 2%.
+/*
+ * @param     Combatant    attacker
+ * @param     Combatant    defender
+ *
+ * @return    int          chance to hit, from 5 to 95
+ */
+func HitChance(attacker, defender Combatant) int {
+
+	chance := baseHitChance + 2*(int(attacker.Agility)-int(defender.Agility))
+
+	return clamp(chance, minHitChance, maxHitChance)
+}
+
+// CritChance ... percentage chance of an attack being a critical hit;
+// every 2 points of Agility above average adds 1%. A critical hit can
+// never be more likely than a clean hit.
+/*
+ * @param     Combatant    attacker
+ * @param     Combatant    defender
+ *
+ * @return    int          chance of a critical hit
+ */
+func CritChance(attacker, defender Combatant) int {
+
+	chance := baseCritChance + (int(attacker.Agility)-averageAttribute)/2
+	chance = clamp(chance, minCritChance, maxCritChance)
+
+	return clamp(chance, 0, HitChance(attacker, defender)-glancingBand)
+}
+
+// StrengthBonus ... extra damage for every 4 points of Strength above
+// average, or less damage for a weak creature.
+/*
+ * @param     uint    strength
+ *
+ * @return    int     damage bonus
+ */
+func StrengthBonus(strength uint) int {
+	return (int(strength) - averageAttribute) / 4
+}
+
+// Damage ... damage done by an attack with the given outcome.
+/*
+ * @param     Combatant    attacker
+ * @param     Combatant    defender
+ * @param     Outcome      how well the attack landed
+ *
+ * @return    int          damage done, never negative
+ */
+func Damage(attacker, defender Combatant, outcome Outcome) int {
+
+	damage := attacker.Attack + StrengthBonus(attacker.Strength) -
+		defender.Defence
+
+	switch outcome {
+	case Miss:
+		return 0
+
+	// Half damage, which may well be none at all.
+	case Glancing:
+		return clamp(damage/2, 0, damage)
+
+	// A critical hit always hurts, even thru heavy armour.
+	case Critical:
+		return 2 * clamp(damage, 1, damage)
+	}
+
+	// A clean hit always does at least a point of damage.
+	return clamp(damage, 1, damage)
+}
+
+// clamp ... keep a value between a minimum and maximum.
+func clamp(value, minimum, maximum int) int {
+
+	if value > maximum {
+		value = maximum
+	}
+	if value < minimum {
+		value = minimum
+	}
+
+	return value
+}
@@ -0,0 +1,151 @@
+/*
+ * File: combat/combat_test.go
+ *
+ * Description: Checks the combat maths: the bounds on every chance, which
+ *              rolls land as which outcome, and the damage of each.
+ */
+
+package combat
+
+import "testing"
+
+// fixedRoll ... Roller that always rolls the same number
+type fixedRoll int
+
+func (f fixedRoll) Intn(n int) int {
+	return int(f) % n
+}
+
+// average ... a combatant with no bonus or penalty from its attributes
+func average(attack, defence int) Combatant {
+	return Combatant{attack, defence, averageAttribute, averageAttribute}
+}
+
+func TestHitChance(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		attacker uint
+		defender uint
+		want     int
+	}{
+		{"even", 10, 10, 75},
+		{"more agile attacker", 14, 10, 83},
+		{"more agile defender", 10, 14, 67},
+		{"clamped high", 40, 0, maxHitChance},
+		{"clamped low", 0, 40, minHitChance},
+	}
+
+	for _, tt := range tests {
+		attacker := Combatant{Agility: tt.attacker}
+		defender := Combatant{Agility: tt.defender}
+		if got := HitChance(attacker, defender); got != tt.want {
+			t.Errorf("%s: HitChance() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCritChance(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		attacker uint
+		defender uint
+		want     int
+	}{
+		{"average", 10, 10, 5},
+		{"agile", 16, 10, 8},
+		{"clamped high", 100, 10, maxCritChance},
+		{"clamped low", 0, 0, minCritChance},
+
+		// A hit chance of 5% leaves no room for a critical hit at all.
+		{"never above a clean hit", 10, 60, 0},
+	}
+
+	for _, tt := range tests {
+		attacker := Combatant{Agility: tt.attacker}
+		defender := Combatant{Agility: tt.defender}
+		if got := CritChance(attacker, defender); got != tt.want {
+			t.Errorf("%s: CritChance() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOutcomeOf(t *testing.T) {
+
+	tests := []struct {
+		roll int
+		want Outcome
+	}{
+		{0, Critical},
+		{4, Critical},
+		{5, Hit},
+		{64, Hit},
+		{65, Glancing},
+		{74, Glancing},
+		{75, Miss},
+		{99, Miss},
+	}
+
+	for _, tt := range tests {
+		if got := OutcomeOf(tt.roll, 75, 5); got != tt.want {
+			t.Errorf("OutcomeOf(%d, 75, 5) = %d, want %d", tt.roll, got,
+				tt.want)
+		}
+	}
+}
+
+func TestDamage(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		attacker Combatant
+		defence  int
+		outcome  Outcome
+		want     int
+	}{
+		{"hit", average(6, 0), 2, Hit, 4},
+		{"glancing", average(6, 0), 1, Glancing, 2},
+		{"critical", average(6, 0), 2, Critical, 8},
+		{"miss", average(6, 0), 2, Miss, 0},
+		{"strong", Combatant{6, 0, 18, 10}, 2, Hit, 6},
+		{"weak", Combatant{6, 0, 2, 10}, 2, Hit, 2},
+
+		// Base damage below zero, as against heavy armour.
+		{"negative hit", average(1, 0), 5, Hit, 1},
+		{"negative glancing", average(1, 0), 5, Glancing, 0},
+		{"negative critical", average(1, 0), 5, Critical, 2},
+		{"negative miss", average(1, 0), 5, Miss, 0},
+	}
+
+	for _, tt := range tests {
+		defender := average(0, tt.defence)
+		if got := Damage(tt.attacker, defender, tt.outcome); got != tt.want {
+			t.Errorf("%s: Damage() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+
+	tests := []struct {
+		roll int
+		want Outcome
+		dmg  int
+	}{
+		{0, Critical, 8},
+		{30, Hit, 4},
+		{70, Glancing, 2},
+		{90, Miss, 0},
+	}
+
+	for _, tt := range tests {
+		got := Resolve(average(6, 0), average(0, 2), fixedRoll(tt.roll))
+		if got.Outcome != tt.want || got.Damage != tt.dmg ||
+			got.Roll != tt.roll || got.HitChance != 75 ||
+			got.CritChance != 5 {
+			t.Errorf("Resolve() with roll %d = %+v, want outcome %d and "+
+				"damage %d", tt.roll, got, tt.want, tt.dmg)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+/*
+ * File: consumable.go
+ *
+ * Description: Handles items that are used up, such as potions, scrolls
+ *              and food, whose effects are defined by their item type.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/rbisewski/go_roguelike/types"
+)
+
+// itemEffects ... how each kind of item effect is carried out, keyed by
+// the effect type given in the item type data
+var itemEffects = map[string]func(g *Game, p *Creature, amount int){
+	types.EffectHeal:         effectHeal,
+	types.EffectRestoreMana:  effectRestoreMana,
+	types.EffectTeleport:     effectTeleport,
+	types.EffectMagicMapping: effectMagicMapping,
+	types.EffectRepair:       effectRepair,
+}
+
+// UseInventoryItem ... use up an item from the inventory screen, e.g.
+// quaff a potion
+/*
+ * @param     Game*    pointer to the current game object
+ * @param     string   the given key that was pressed
+ *
+ * @return    bool     whether or not an item was used
+ *            error    error message, if any
+ */
+func UseInventoryItem(g *Game, key string) (bool, error) {
+
+	if g == nil || g.Player == nil || len(key) < 1 {
+		return false, fmt.Errorf("UseInventoryItem() --> invalid input")
+	}
+
+	// Only the letters beside the listed items select one.
+	index, ok := inventoryView(g).Selected(key)
+	if !ok {
+		return false, nil
+	}
+
+	return g.Player.useItem(g, g.Player.inventory[index]), nil
+}
+
+// useItem ... carry out every effect of an item, and then use it up.
+/*
+ * @param     Game*    pointer to the current game object
+ * @param     Item*    item being used
+ *
+ * @return    bool     whether or not the item could be used
+ */
+func (m *Creature) useItem(g *Game, itm *Item) bool {
+
+	if len(itm.effects) == 0 {
+		m.notify(fmt.Sprintf("You cannot %s the %s.", itm.useVerb, itm.name))
+		return false
+	}
+
+	// Shop wares have to be bought before they can be used up.
+	if itm.unpaid {
+		m.notify(fmt.Sprintf("You must pay for the %s first.", itm.name))
+		return false
+	}
+
+	// A repair kit is kept for later when there is nothing to repair.
+	for _, effect := range itm.effects {
+		if effect.Type == types.EffectRepair && m.mostWornItem() == nil {
+			m.notify("You have nothing in need of repair.")
+			return false
+		}
+	}
+
+	m.notify(fmt.Sprintf("You %s the %s.", itm.useVerb, itm.name))
+
+	for _, effect := range itm.effects {
+
+		apply, exists := itemEffects[effect.Type]
+		if !exists {
+			DebugLog(g, fmt.Sprintf("useItem() --> unknown effect %q of "+
+				"item [%s]", effect.Type, itm.name))
+			continue
+		}
+
+		apply(g, m, effect.Amount)
+	}
+
+	// Use up one item from the stack.
+	itm.quantity--
+	if itm.quantity < 1 {
+		m.removeFromInventory(itm)
+	}
+	m.checkEncumbrance()
+
+	return true
+}
+
+// effectHeal ... restore the given number of hit points.
+func effectHeal(g *Game, p *Creature, amount int) {
+	p.notify(fmt.Sprintf("You recover %d hit points.", restoreHp(p, amount)))
+}
+
+// effectRestoreMana ... restore the given amount of mana, if the user has
+// any mana to begin with.
+func effectRestoreMana(g *Game, p *Creature, amount int) {
+
+	maxMana := p.maxMana()
+	if maxMana == 0 {
+		p.notify("You feel a brief tingle, but nothing more.")
+		return
+	}
+
+	if p.Mana+amount > maxMana {
+		amount = maxMana - p.Mana
+	}
+	p.Mana += amount
+
+	p.notify(fmt.Sprintf("You recover %d mana.", amount))
+}
+
+// effectTeleport ... move the user to a random open spot on the level.
+func effectTeleport(g *Game, p *Creature, amount int) {
+
+	a := p.area
+	spots := make([]Coords, 0)
+
+	for y := 0; y < a.Height; y++ {
+		for x := 0; x < a.Width; x++ {
+
+			_, blocks, hasCreature, _ := a.GetTileInfo(y, x)
+			if !blocks && hasCreature == nil {
+				spots = append(spots, Coords{y: y, x: x})
+			}
+		}
+	}
+
+	if len(spots) == 0 {
+		p.notify("You feel a brief tug, but nothing more.")
+		return
+	}
+
+	spot := spots[g.rng.Combat.Intn(len(spots))]
+	p.Y, p.X = spot.y, spot.x
+
+	p.notify("You are whisked away to another part of the level.")
+}
+
+// effectMagicMapping ... reveal every open tile of the level, along with
+// the walls around them.
+func effectMagicMapping(g *Game, p *Creature, amount int) {
+
+	a := p.area
+	if len(a.Explored) != len(a.Tiles) {
+		return
+	}
+
+	for y := 0; y < a.Height; y++ {
+		for x := 0; x < a.Width; x++ {
+
+			if a.Tiles[x+y*a.Width].BlockMove {
+				continue
+			}
+
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if y+dy >= 0 && y+dy < a.Height && x+dx >= 0 &&
+						x+dx < a.Width {
+						a.Explored[(x+dx)+(y+dy)*a.Width] = true
+					}
+				}
+			}
+		}
+	}
+
+	p.notify("The layout of the level comes to mind.")
+}
+
+// effectRepair ... restore the given durability to the most worn item the
+// user has, mending it if it was broken.
+func effectRepair(g *Game, p *Creature, amount int) {
+
+	itm := p.mostWornItem()
+	if itm == nil {
+		p.notify("You have nothing in need of repair.")
+		return
+	}
+
+	wasBroken := itm.isBroken
+	itm.adjustDurability(Min(amount,
+		itm.durabilityMaximum-itm.durabilityCurrent))
+
+	switch {
+	case wasBroken && !itm.isBroken:
+		p.notify(fmt.Sprintf("You mend the broken %s.", itm.name))
+	case itm.worn():
+		p.notify(fmt.Sprintf("You patch up the %s.", itm.name))
+	default:
+		p.notify(fmt.Sprintf("The %s is as good as new.", itm.name))
+	}
+}
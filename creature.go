@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/rbisewski/go_roguelike/combat"
 	"github.com/rbisewski/go_roguelike/types"
 )
 
@@ -58,6 +59,43 @@ type Creature struct {
 	// The number of steps currently walked by the creature in question.
 	Healcounter uint
 
+	// Behaviour profile used by the monster AI, e.g. "ambusher".
+	behaviour string
+
+	// Where the creature was spawned, which territorial monsters guard.
+	homeY int
+	homeX int
+
+	// Character level and experience gained so far.
+	Level      int
+	Experience int
+
+	// Experience awarded to the player for killing the creature.
+	experienceValue int
+
+	// Mana left to spend on spells, and the turns left before each ability
+	// can be used again, keyed by name.
+	Mana      int
+	cooldowns map[string]int
+
+	// Gold coins carried.
+	Gold int
+
+	// Encumbrance the player was last told of, and the steps taken while
+	// encumbered, every so many of which are lost.
+	burden         Encumbrance
+	staggerCounter int
+
+	// Status effects the creature is suffering from, in the order they
+	// took hold, and those its attacks may inflict.
+	statuses []StatusEffect
+	onHit    []types.HitEffect
+
+	// How quickly the creature acts, where normalSpeed is the pace of an
+	// ordinary creature, and the energy it has built up towards acting.
+	speed  int
+	energy int
+
 	// Pointer to the creature equipment locations.
 	*equipment
 }
@@ -104,6 +142,8 @@ func newEquipment(head, neck, torso, righthand, lefthand,
  * @param     int          defence
  * @param     uint         heal rate
  * @param     uint         heal counter
+ * @param     string       behaviour profile
+ * @param     int          experience awarded for killing the creature
  *
  * @return    Creature*    pointer to a Creature w/ Stats
  */
@@ -124,7 +164,9 @@ func NewCreature(name string,
 	agl uint,
 	wis uint,
 	hr uint,
-	hc uint) *Creature {
+	hc uint,
+	behaviour string,
+	xp int) *Creature {
 
 	// Assign memory for a creature object and return the address.
 	return &Creature{name,
@@ -145,6 +187,21 @@ func NewCreature(name string,
 		wis,
 		hr,
 		hc,
+		behaviour,
+		y,
+		x,
+		1,
+		0,
+		xp,
+		0,
+		nil,
+		0,
+		Unencumbered,
+		0,
+		nil,
+		nil,
+		normalSpeed,
+		turnEnergy,
 		nil}
 }
 
@@ -203,6 +260,21 @@ func NewCreatureWithEquipment(name string,
 		wis,
 		hr,
 		hc,
+		"",
+		y,
+		x,
+		1,
+		0,
+		0,
+		0,
+		make(map[string]int),
+		0,
+		Unencumbered,
+		0,
+		nil,
+		nil,
+		normalSpeed,
+		turnEnergy,
 		newEquipment(nil, nil, nil, nil, nil, nil)}
 }
 
@@ -225,6 +297,11 @@ func (m *Creature) Move(y, x int) {
 		return
 	}
 
+	// Stunned creatures can neither move nor fight.
+	if m.stunned() {
+		return
+	}
+
 	// Since this has a creature and it appears to have valid coords, then
 	// go ahead and test it again the tile the creature in question wishes
 	// to move to.
@@ -235,23 +312,6 @@ func (m *Creature) Move(y, x int) {
 		return
 	}
 
-	// Increment the monster's healing counter.
-	m.Healcounter++
-
-	// If the heal counter has surpassed the healing rate value, then...
-	if m.Healcounter >= m.Healrate {
-
-		// Set the counter back to zero
-		m.Healcounter = 0
-	}
-
-	// If the healing counter is zero and creature is not fully healed...
-	if m.Healcounter == 0 && m.MaxHp > m.Hp {
-
-		// Increase the current hitpoints of the creature by 1
-		m.Hp++
-	}
-
 	// If the player attempts to move to a blocking tile, and it is a wall,
 	// go ahead and print a short message and then leave function.
 	if blocks && m.species == "player" && tileRune == '#' {
@@ -259,6 +319,12 @@ func (m *Creature) Move(y, x int) {
 		return
 	}
 
+	// Locked chests can only be opened by picking the lock.
+	if blocks && m.species == "player" && tileRune == chestRune {
+		MessageLog.log("The chest is locked.")
+		return
+	}
+
 	// Catch-all message for when the player moves into a blocking tile.
 	if blocks && m.species == "player" {
 		MessageLog.log("Something here is blocking, and you cannot move past.")
@@ -280,6 +346,13 @@ func (m *Creature) Move(y, x int) {
 	// If the tile is non-blocking, but a creature is here, go ahead and
 	// switch to combat mode via the attack() function.
 	if hasCreature != nil && m != hasCreature {
+
+		// Walking into a shopkeeper is how the player trades with them.
+		if m.species == "player" && hasCreature.isShopkeeper() {
+			G.openShop(hasCreature)
+			return
+		}
+
 		DebugLog(&G, fmt.Sprintf(
 			"The %s is attacking %s at location (%d,%d).",
 			m.name,
@@ -288,6 +361,20 @@ func (m *Creature) Move(y, x int) {
 			m.X+x))
 
 		m.attack(hasCreature)
+		m.spend(actionAttack)
+		return
+	}
+
+	// Creatures caught in webs cannot move, though they can still fight.
+	if m.hasStatus(types.StatusEntangled) {
+		m.notify("You struggle against the webs.")
+		m.spend(actionMove)
+		return
+	}
+
+	// Creatures weighed down by what they carry lose some of their steps,
+	// though not their attacks.
+	if !m.canStep() {
 		return
 	}
 
@@ -302,6 +389,7 @@ func (m *Creature) Move(y, x int) {
 	// go ahead and move there.
 	m.Y += y
 	m.X += x
+	m.spend(actionMove)
 
 	// If there are items laying on the ground, give the player some
 	// indicator of what is there.
@@ -316,6 +404,31 @@ func (m *Creature) Move(y, x int) {
 	}
 }
 
+// tickHealing ... heal the creature by a hit point once every so many
+// turns, as given by its healing rate.
+/*
+ * @return    none
+ */
+func (m *Creature) tickHealing() {
+
+	// Increment the creature's healing counter.
+	m.Healcounter++
+
+	// If the heal counter has surpassed the healing rate value, then...
+	if m.Healcounter >= m.Healrate {
+
+		// Set the counter back to zero
+		m.Healcounter = 0
+	}
+
+	// If the healing counter is zero and creature is not fully healed...
+	if m.Healcounter == 0 && m.MaxHp > m.Hp {
+
+		// Increase the current hitpoints of the creature by 1
+		m.Hp++
+	}
+}
+
 //! Function to handle what occurs if a monster attacks.
 /*
  * @param     Creature*    defending creature / PC
@@ -329,12 +442,51 @@ func (m *Creature) attack(defender *Creature) {
 		return
 	}
 
-	var damageDealt int = m.Att - defender.Def
+	// Roll to see whether the attack lands, and how much damage it does.
+	m.meleeStrike(defender, combat.Resolve(m.combatant(),
+		defender.combatant(), G.rng.Combat))
+}
 
-	// Cap the damage dealt at zero, this is to prevent the enemies from
-	// accidently healing other creatures when they attack.
-	if damageDealt < 0 {
-		damageDealt = 0
+// meleeStrike ... apply the result of a blow struck in hand to hand
+// combat; weapons wear down as they land blows, and armour as it takes
+// them, and the blow may inflict status effects, e.g. poison.
+/*
+ * @param     Creature*    defending creature / PC
+ * @param     Result       outcome and damage of the attack
+ *
+ * @return    none
+ */
+func (m *Creature) meleeStrike(defender *Creature, result combat.Result) {
+
+	m.strike(defender, result)
+
+	if result.Outcome != combat.Miss {
+		m.wearEquipment(weaponWear, G.rng.Combat)
+		defender.wearEquipment(armourWear, G.rng.Combat)
+		m.inflictOnHit(defender, G.rng.Combat)
+	}
+}
+
+// strike ... apply the result of an attack, or of an ability, to the
+// creature being attacked and tell the player what happened.
+/*
+ * @param     Creature*    defending creature / PC
+ * @param     Result       outcome and damage of the attack
+ *
+ * @return    none
+ */
+func (m *Creature) strike(defender *Creature, result combat.Result) {
+
+	if m == nil || defender == nil {
+		DebugLog(&G, "strike() --> invalid creature input.")
+		return
+	}
+
+	damageDealt := result.Damage
+
+	// Shopkeepers do not take kindly to being attacked.
+	if m.species == "player" && defender.isShopkeeper() {
+		defender.angerShopkeeper()
 	}
 
 	// Adjust the defender's HP based on the damage dealt.
@@ -349,23 +501,47 @@ func (m *Creature) attack(defender *Creature) {
 		return
 	}
 
-	// Print a message telling the end-user they have been injured
-	// during the attack.
+	// Print a message telling the end-user how badly they have been
+	// injured during the attack.
 	if defender.species == "player" {
-		MessageLog.log(fmt.Sprintf("The %s injures you for %d hit points.",
-			m.name, damageDealt))
+		switch result.Outcome {
+		case combat.Miss:
+			MessageLog.log(fmt.Sprintf("The %s misses you.", m.name))
+		case combat.Glancing:
+			MessageLog.log(fmt.Sprintf("The %s grazes you for %d hit points.",
+				m.name, damageDealt))
+		case combat.Critical:
+			MessageLog.log(fmt.Sprintf("The %s critically wounds you for %d "+
+				"hit points!", m.name, damageDealt))
+		default:
+			MessageLog.log(fmt.Sprintf("The %s injures you for %d hit points.",
+				m.name, damageDealt))
+		}
 		return
 	}
 
 	// Otherwise the player is doing the attack, so explain how much damage
 	// was done to the creature being attacked.
-	MessageLog.log(fmt.Sprintf("You strike the %s for %d hit points "+
-		"of damage.", defender.name, damageDealt))
+	switch result.Outcome {
+	case combat.Miss:
+		MessageLog.log(fmt.Sprintf("You miss the %s.", defender.name))
+		return
+	case combat.Glancing:
+		MessageLog.log(fmt.Sprintf("Your blow glances off the %s for %d hit "+
+			"points of damage.", defender.name, damageDealt))
+	case combat.Critical:
+		MessageLog.log(fmt.Sprintf("You land a critical blow on the %s for "+
+			"%d hit points of damage!", defender.name, damageDealt))
+	default:
+		MessageLog.log(fmt.Sprintf("You strike the %s for %d hit points "+
+			"of damage.", defender.name, damageDealt))
+	}
 
 	// If creature being attacked has reached zero hit points, go ahead and
 	// print a message stating that the creature has died.
 	if defender.Hp < 1 {
 		MessageLog.log(fmt.Sprintf("The %s has died.", defender.name))
+		m.gainExperience(defender.experienceValue)
 		return
 	}
 
@@ -397,6 +573,15 @@ func (m *Creature) attack(defender *Creature) {
 	}
 }
 
+// combatant ... the numbers of the creature used to resolve attacks.
+/*
+ * @return    Combatant    attack, defence, strength and agility
+ */
+func (m *Creature) combatant() combat.Combatant {
+	return combat.Combatant{Attack: m.attackValue(),
+		Defence: m.defenceValue(), Strength: m.Strength, Agility: m.Agility}
+}
+
 //! Function to handle what occurs when a monster dies.
 /*
  * @param     Creature*    monster who is currently dying
@@ -445,26 +630,15 @@ func (m *Creature) die() {
 		m.area.Creatures = append(m.area.Creatures[:i], m.area.Creatures[i+1:]...)
 	}
 
-	// Check if the monster in question has no inventory, or an empty
-	// inventory, then this is done.
-	if m.inventory == nil || len(m.inventory) < 1 {
-
-		// Variable declaration.
-		var corpse *Item
+	// Create an item that consists of the monster corpse.
+	corpse := NewItem(fmt.Sprintf("corpse of %s", m.name), "corpse",
+		m.Y, m.X, '%', m.area, false, false, 0, 0, 0, 0, 10, 0, 0)
 
-		// Create an item that consists of the monster corpse.
-		corpse = NewItem(fmt.Sprintf("corpse of %s", m.name), "corpse",
-			m.Y, m.X, '%', m.area, false, false, 0, 0, 0, 0, 10, 0, 0)
+	// Leave a creature corpse item in the shape of a % at the given
+	// vertex (x,y) location of the formerly alive monster.
+	m.area.Items = append(m.area.Items, corpse)
 
-		// Leave a creature corpse item in the shape of a % at the given
-		// vertex (x,y) location of the formerly alive monster.
-		m.area.Items = append(m.area.Items, corpse)
-
-		// All is now done.
-		return
-	}
-
-	// Otherwise, attempt to add all of the inventory items to the ground.
+	// Then drop everything the monster was carrying on top of it.
 	for i, item := range m.inventory {
 
 		// Sanity check, make sure this actually got a valid item.
@@ -474,11 +648,18 @@ func (m *Creature) die() {
 			continue
 		}
 
-		// Give the item a % rune shape, and set it to the (x,y) coord of
-		// dead monster.
-		item.ch = '%'
+		// Set it to the (x,y) coord of the dead monster.
 		item.X = m.X
 		item.Y = m.Y
+		item.area = m.area
 		m.area.Items = append(m.area.Items, item)
 	}
+	m.inventory = nil
+
+	// Along with any gold it had.
+	if m.Gold > 0 {
+		m.area.Items = append(m.area.Items, newGoldPile(m.Gold, m.Y, m.X,
+			m.area))
+		m.Gold = 0
+	}
 }
@@ -0,0 +1,286 @@
+/*
+ * File: curses.go
+ *
+ * Description: ncurses implementation of the Renderer and InputSource
+ *              interfaces.
+ */
+
+package main
+
+import (
+	"errors"
+
+	"github.com/rbisewski/gocurses"
+)
+
+// CursesRenderer ... Renderer that draws to the terminal via ncurses.
+type CursesRenderer struct {
+
+	// Gameplay screen section where the level information is rendered.
+	pad *gocurses.Window
+
+	// Player stats section.
+	stats *gocurses.Window
+
+	// Window for debug information.
+	debug *gocurses.Window
+
+	// Section of the window where in-game messages are displayed.
+	log *gocurses.Window
+}
+
+// CursesInput ... InputSource that reads keys from the ncurses terminal.
+type CursesInput struct {
+
+	// Number of reads in a row that failed, e.g. as there is no terminal.
+	failures int
+}
+
+// maxInputFailures ... failed reads in a row before the terminal is
+// given up on
+const maxInputFailures = 100
+
+// errInputClosed ... raised when the terminal can no longer be read,
+// e.g. because the game was started without one.
+var errInputClosed = errors.New("the keyboard can no longer be read")
+
+// Start ... initialize ncurses and determine the console size.
+/*
+ * @return    int    console height
+ * @return    int    console width
+ */
+func (r *CursesRenderer) Start() (int, int) {
+
+	// Setup the screen.
+	gocurses.Initscr()
+
+	// Send the terminal a 'break'
+	gocurses.Cbreak()
+
+	// Tell console that this isn't going to send data back to bash, etc.
+	gocurses.Noecho()
+
+	// Tell console this'll be using the keypad.
+	gocurses.Stdscr.Keypad(true)
+
+	// Nullify the curses set.
+	gocurses.CursSet(0)
+
+	// No colours? Then give up here via panic()
+	if !gocurses.HasColors() {
+		panic("Panic: Console does not use colours!")
+	}
+
+	// Since we otherwise have colours, go ahead and just run it.
+	gocurses.StartColor()
+
+	// Initialize the colours from the ncurses definitions.
+	r.initColours()
+
+	// Figure out the limits of the provided console.
+	return gocurses.Getmaxyx()
+}
+
+// initColours ... Function to initialize the colours needed by gocurses.
+/*
+ * @return   none
+ */
+func (r *CursesRenderer) initColours() {
+
+	// Initialize a red-black colour pair (for corpses, etc)
+	gocurses.InitPair(1, gocurses.COLOR_RED, gocurses.COLOR_BLACK)
+
+	// Initialize a yellow-black colour pair (for walls, etc)
+	gocurses.InitPair(2, gocurses.COLOR_YELLOW, gocurses.COLOR_BLACK)
+
+	// Initialize a magenta-black colour pair (for items, etc)
+	gocurses.InitPair(3, gocurses.COLOR_MAGENTA, gocurses.COLOR_BLACK)
+
+	// Initialize a blue-black colour pair (for remembered tiles, etc)
+	gocurses.InitPair(4, gocurses.COLOR_BLUE, gocurses.COLOR_BLACK)
+}
+
+// Layout ... create the stats, debug and message log windows.
+/*
+ * @param     int    gamepad viewscreen height
+ * @param     int    gamepad viewscreen width
+ * @param     int    console height
+ * @param     int    console width
+ *
+ * @return    none
+ */
+func (r *CursesRenderer) Layout(screenHeight, screenWidth, consoleHeight,
+	consoleWidth int) {
+
+	// Carve out another section for the stats viewscreen.
+	r.stats = gocurses.NewWindow(screenHeight,
+		consoleWidth-screenWidth,
+		0,
+		screenWidth+1)
+
+	// Assign some space for the debug message section.
+	r.debug = gocurses.NewWindow(5,
+		consoleWidth,
+		consoleHeight-1,
+		1)
+
+	// Need in-game messages for those times when the player runs into the
+	// wall or kills a monster, and the like...
+	r.log = gocurses.NewPad(100, screenWidth)
+}
+
+// End ... send the end() ncurse to this game.
+func (r *CursesRenderer) End() {
+	gocurses.End()
+}
+
+// Clear ... send the clear() ncurse to this game.
+func (r *CursesRenderer) Clear() {
+	gocurses.Clear()
+}
+
+// NewPad ... allocate a new ncurses pad for the area map.
+/*
+ * @param     int     height
+ * @param     int     width
+ *
+ * @return    bool    whether or not the pad could be created
+ */
+func (r *CursesRenderer) NewPad(h, w int) bool {
+	r.pad = gocurses.NewPad(h, w)
+	return r.pad != nil
+}
+
+// DrawPad ... draw a rune onto the pad, with an optional colour pair.
+/*
+ * @param     int     y-value
+ * @param     int     x-value
+ * @param     rune    ASCII character graphic
+ * @param     int     colour value, or 0 for none
+ *
+ * @return    none
+ */
+func (r *CursesRenderer) DrawPad(y, x int, ch rune, colour int) {
+
+	if r.pad == nil {
+		return
+	}
+
+	// No colour? Then simply add the character.
+	if colour == 0 {
+		r.pad.Mvaddch(y, x, ch)
+		return
+	}
+
+	// Apply a colour filter to the character drawing.
+	r.pad.Attron(gocurses.ColorPair(colour))
+
+	// Add the character to the specific location.
+	r.pad.Mvaddch(y, x, ch)
+
+	// Revert the given filter back to the original console colours afterwards.
+	r.pad.Attroff(gocurses.ColorPair(colour))
+}
+
+// RefreshPad ... queue the visible portion of the pad for output.
+/*
+ * @param     int    y-value of the top-left corner of the pad section
+ * @param     int    x-value of the top-left corner of the pad section
+ * @param     int    height of the section
+ * @param     int    width of the section
+ *
+ * @return    none
+ */
+func (r *CursesRenderer) RefreshPad(fromY, fromX, height, width int) {
+
+	if r.pad == nil {
+		return
+	}
+
+	r.pad.PnoutRefresh(fromY, fromX, 0, 0, height-1, width-1)
+}
+
+// Write ... write a string onto stdscr.
+func (r *CursesRenderer) Write(y, x int, s string) {
+	gocurses.Mvaddstr(y, x, s)
+}
+
+// WriteStats ... write a string onto the stats window.
+func (r *CursesRenderer) WriteStats(y, x int, s string) {
+	if r.stats != nil {
+		r.stats.Mvaddstr(y, x, s)
+	}
+}
+
+// RefreshStats ... queue the stats window for output.
+func (r *CursesRenderer) RefreshStats() {
+	if r.stats != nil {
+		r.stats.NoutRefresh()
+	}
+}
+
+// WriteLog ... write a string onto the message log pad.
+func (r *CursesRenderer) WriteLog(line int, s string) {
+	if r.log != nil {
+		r.log.Mvaddstr(line, 0, s)
+	}
+}
+
+// RefreshLog ... queue the visible portion of the message log for output.
+/*
+ * @param     int    first line of the log pad to display
+ * @param     int    console row where the log begins
+ * @param     int    console row where the log ends
+ * @param     int    console width
+ *
+ * @return    none
+ */
+func (r *CursesRenderer) RefreshLog(fromLine, top, bottom, width int) {
+	if r.log != nil {
+		r.log.PnoutRefresh(fromLine, 0, top, 0, bottom, width)
+	}
+}
+
+// WriteDebug ... replace the contents of the debug window.
+func (r *CursesRenderer) WriteDebug(s string) {
+
+	if r.debug == nil {
+		return
+	}
+
+	// Add some " " buffers to the character pad.
+	r.debug.Mvaddstr(0, 0, "                         ")
+
+	// Add the given output message.
+	r.debug.Mvaddstr(0, 0, s)
+
+	// Refresh
+	r.debug.NoutRefresh()
+}
+
+// Update ... push all of the queued changes to the terminal.
+func (r *CursesRenderer) Update() {
+	gocurses.Doupdate()
+}
+
+// GetKey ... Grab the keyboard input and then pass back a string.
+/*
+ * @return    string    Keyboard ASCII character input (Getch() = get character)
+ */
+func (in *CursesInput) GetKey() string {
+
+	ch := gocurses.Getch()
+
+	// A failed read is no key at all, rather than a key of its own; and
+	// if the reads keep failing, there is nobody left to play.
+	if ch == gocurses.ERR {
+		in.failures++
+		if in.failures >= maxInputFailures {
+			panic(errInputClosed)
+		}
+		return ""
+	}
+	in.failures = 0
+
+	return string(rune(ch))
+}
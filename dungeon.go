@@ -0,0 +1,281 @@
+/*
+ * File: dungeon.go
+ *
+ * Description: Handles the dungeon, which is a stack of numbered areas
+ *              joined together by staircases.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// DungeonHeight ... height of every level in the dungeon
+const DungeonHeight = 240
+
+// DungeonWidth ... width of every level in the dungeon
+const DungeonWidth = 250
+
+// MaxDepth ... deepest level of the dungeon; it has no down staircase
+const MaxDepth = 10
+
+// Dungeon ... Structure to hold every level that has been generated.
+type Dungeon struct {
+
+	// Levels of the dungeon, indexed by depth (starting at 1).
+	Levels map[int]*Area
+
+	// Seed that every level of the dungeon is generated from.
+	Seed int64
+}
+
+// NewDungeon ... Dungeon constructor.
+/*
+ * @param     int64       game seed
+ *
+ * @return    Dungeon*    pointer to a dungeon with no levels yet
+ */
+func NewDungeon(seed int64) *Dungeon {
+	return &Dungeon{make(map[int]*Area), seed}
+}
+
+// Level ... grab the area at the given depth, generating it on the first
+// visit so that it keeps its state for every visit afterwards.
+/*
+ * @param     int               depth of the level
+ * @param     RandomStreams*    random number streams of the game
+ *
+ * @return    Area*             pointer to the level
+ */
+func (d *Dungeon) Level(depth int, r *RandomStreams) *Area {
+
+	if d == nil || r == nil || depth < 1 || depth > MaxDepth {
+		DebugLog(&G, fmt.Sprintf("Level() --> invalid input"))
+		return nil
+	}
+
+	// The level was visited before, so hand it back as it was left.
+	if a, exists := d.Levels[depth]; exists {
+		return a
+	}
+
+	// Generate a brand new level, from streams seeded for this depth.
+	r.ForLevel(d.Seed, depth)
+	a, y, x := NewArea(DungeonHeight, DungeonWidth, levelGenerator(depth),
+		r.Map)
+	if a == nil {
+		return nil
+	}
+	a.Depth = depth
+
+	// Join the level to the ones above and below via staircases.
+	a.placeStairs(y, x, r.Spawn)
+
+	// Some levels have a shop, tucked away in the rock.
+	if r.Spawn.Intn(100) < shopChance {
+		a.placeShop(r.Spawn)
+	}
+
+	// Levels may also hold a few locked chests, for a thief to pick open.
+	a.placeChests(r.Spawn)
+
+	// Pass along the area, and populate the world with a number of monsters
+	// and items.
+	a.populateAreaWithCreatures(r.Spawn)
+	a.populateAreaWithItems(r.Spawn)
+
+	d.Levels[depth] = a
+
+	return a
+}
+
+// placeStairs ... place the up staircase at the starting point of a
+// level, and the down staircase at a random tile reachable from there.
+/*
+ * @param     int          y-value of the starting point
+ * @param     int          x-value of the starting point
+ * @param     rand.Rand*   spawning random number stream
+ *
+ * @return    none
+ */
+func (a *Area) placeStairs(y, x int, r *rand.Rand) {
+
+	if a == nil {
+		DebugLog(&G, fmt.Sprintf("placeStairs() --> invalid input"))
+		return
+	}
+
+	// The starting point is where the player arrives from above; the
+	// first level has nothing above it, so no staircase is drawn there.
+	a.UpY, a.UpX = y, x
+	if a.Depth > 1 {
+		a.Tiles[x+y*a.Width] = Tile{'<', false, false}
+	}
+
+	// The deepest level has nothing below it.
+	if a.Depth >= MaxDepth {
+		a.DownY, a.DownX = y, x
+		return
+	}
+
+	// Pick a random tile that can be walked to from the starting point,
+	// so that the player is never cut off from the next level.
+	reachable := a.reachableTiles(y, x)
+	if len(reachable) < 2 {
+		a.DownY, a.DownX = y, x
+		return
+	}
+
+	tile := reachable[1+getRandomNumBetweenZeroAndMax(r, len(reachable)-1)]
+	a.DownY, a.DownX = tile/a.Width, tile%a.Width
+	a.Tiles[tile] = Tile{'>', false, false}
+}
+
+// reachableTiles ... list every non-blocking tile connected to (x,y).
+/*
+ * @param     int      y-value
+ * @param     int      x-value
+ *
+ * @return    int[]    tile indexes, in order of distance from (x,y)
+ */
+func (a *Area) reachableTiles(y, x int) []int {
+
+	if a == nil || y < 0 || y >= a.Height || x < 0 || x >= a.Width {
+		return nil
+	}
+
+	seen := make([]bool, len(a.Tiles))
+	queue := []int{x + y*a.Width}
+	seen[queue[0]] = true
+
+	// Breadth-first search outwards thru the 8 neighbouring tiles.
+	for i := 0; i < len(queue); i++ {
+
+		cy, cx := queue[i]/a.Width, queue[i]%a.Width
+
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+
+				ny, nx := cy+dy, cx+dx
+				if ny < 0 || ny >= a.Height || nx < 0 || nx >= a.Width {
+					continue
+				}
+
+				n := nx + ny*a.Width
+				if seen[n] || a.Tiles[n].BlockMove {
+					continue
+				}
+
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	return queue
+}
+
+// takeStairs ... move the player character up or down a staircase.
+/*
+ * @param     int    direction, -1 for up and +1 for down
+ *
+ * @return    bool   whether or not the player changed level
+ */
+func (g *Game) takeStairs(direction int) bool {
+
+	if g == nil || g.Player == nil || g.Area == nil {
+		DebugLog(&G, fmt.Sprintf("takeStairs() --> invalid input"))
+		return false
+	}
+
+	tile := g.Area.Tiles[g.Player.X+g.Player.Y*g.Area.Width].Ch
+
+	// Make sure the player is standing on the right kind of staircase.
+	if direction > 0 && tile != '>' {
+		MessageLog.log("There is no staircase leading down here.")
+		return false
+	}
+	if direction < 0 && tile != '<' {
+		MessageLog.log("There is no staircase leading up here.")
+		return false
+	}
+
+	next := g.Dungeon.Level(g.Depth+direction, g.rng)
+	if next == nil {
+		return false
+	}
+
+	// Take the player off of the current level...
+	g.Area.removeCreature(g.Player)
+
+	// ...and place them on the matching staircase of the next one.
+	y, x := next.UpY, next.UpX
+	if direction < 0 {
+		y, x = next.DownY, next.DownX
+	}
+	y, x = next.nearestFreeTile(y, x)
+
+	g.Depth += direction
+	g.Area = next
+	g.Player.area = next
+	g.Player.Y, g.Player.X = y, x
+	next.Creatures = append(next.Creatures, g.Player)
+
+	// Give the main game pad the height and width of the new level.
+	SetPad(next.Height, next.Width)
+	Clear()
+
+	if direction > 0 {
+		MessageLog.log(fmt.Sprintf("You descend the staircase to depth %d.",
+			g.Depth))
+	} else {
+		MessageLog.log(fmt.Sprintf("You climb the staircase to depth %d.",
+			g.Depth))
+	}
+
+	return true
+}
+
+// removeCreature ... take a creature out of the list of creatures.
+/*
+ * @param     Creature*    creature to remove
+ *
+ * @return    none
+ */
+func (a *Area) removeCreature(c *Creature) {
+
+	if a == nil || c == nil {
+		return
+	}
+
+	for i, m := range a.Creatures {
+		if m == c {
+			a.Creatures = append(a.Creatures[:i], a.Creatures[i+1:]...)
+			return
+		}
+	}
+}
+
+// nearestFreeTile ... find the closest walkable tile to (x,y) that has no
+// living creature standing on it.
+/*
+ * @param     int    y-value
+ * @param     int    x-value
+ *
+ * @return    int    y-value of the free tile
+ *            int    x-value of the free tile
+ */
+func (a *Area) nearestFreeTile(y, x int) (int, int) {
+
+	for _, tile := range a.reachableTiles(y, x) {
+
+		ty, tx := tile/a.Width, tile%a.Width
+		if _, _, c, _ := a.GetTileInfo(ty, tx); c == nil {
+			return ty, tx
+		}
+	}
+
+	return y, x
+}
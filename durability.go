@@ -0,0 +1,118 @@
+/*
+ * File: durability.go
+ *
+ * Description: Wears down equipment as it is used in combat, and repairs
+ *              it again, whether with a repair kit or at a shop.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// weaponWear ... percentage chance of a wielded weapon losing a point of
+// durability each time it hits, by item category
+var weaponWear = map[string]int{
+	"blade": 20,
+	"blunt": 10,
+}
+
+// armourWear ... percentage chance of a worn item losing a point of
+// durability each time its wearer is hit, by item category
+var armourWear = map[string]int{
+	"shield":   25,
+	"helmet":   15,
+	"armour":   15,
+	"pants":    10,
+	"necklace": 0,
+}
+
+// wearEquipment ... roll for each equipped item with a wear rate whether
+// it loses a point of durability, and tell the wearer of any that break.
+/*
+ * @param     map[string]int    wear rates to use, by item category
+ * @param     rand.Rand*        combat random number stream
+ *
+ * @return    none
+ */
+func (m *Creature) wearEquipment(rates map[string]int, r *rand.Rand) {
+
+	for _, itm := range m.equippedItems() {
+
+		rate, wears := rates[itm.category]
+		if !wears || itm.isBroken || itm.durabilityMaximum < 1 {
+			continue
+		}
+
+		if r.Intn(100) >= rate {
+			continue
+		}
+
+		itm.adjustDurability(-1)
+		if itm.isBroken {
+			m.notify(fmt.Sprintf("Your %s breaks!", itm.name))
+		}
+	}
+}
+
+// worn ... whether the item has lost any of its durability.
+/*
+ * @return    bool    true if the item could be repaired
+ */
+func (itm *Item) worn() bool {
+	return itm.durabilityMaximum > 0 &&
+		itm.durabilityCurrent < itm.durabilityMaximum
+}
+
+// repairPrice ... gold a shopkeeper asks to restore the item to perfect
+// condition, in proportion to the durability it has lost.
+/*
+ * @return    int    price in gold
+ */
+func (itm *Item) repairPrice() int {
+
+	if !itm.worn() {
+		return 0
+	}
+
+	lost := itm.durabilityMaximum - Max(itm.durabilityCurrent, 0)
+
+	return Max(itm.priceToPurchase*lost/itm.durabilityMaximum, 1)
+}
+
+// wornItems ... every item the creature has equipped or is carrying that
+// could be repaired, equipped items first.
+/*
+ * @return    Item[]    the worn items
+ */
+func (m *Creature) wornItems() []*Item {
+
+	items := make([]*Item, 0)
+	for _, itm := range append(m.equippedItems(), m.inventory...) {
+		if itm.worn() {
+			items = append(items, itm)
+		}
+	}
+
+	return items
+}
+
+// mostWornItem ... the item of the creature with the smallest share of its
+// durability left.
+/*
+ * @return    Item*    the most worn item, or nil if nothing is worn
+ */
+func (m *Creature) mostWornItem() *Item {
+
+	var most *Item
+	for _, itm := range m.wornItems() {
+		if most == nil || itm.durabilityCurrent*most.durabilityMaximum <
+			most.durabilityCurrent*itm.durabilityMaximum {
+			most = itm
+		}
+	}
+
+	return most
+}
@@ -0,0 +1,184 @@
+/*
+ * File: encumbrance.go
+ *
+ * Description: Works out how much a creature can carry from its Strength,
+ *              and slows or stops creatures carrying more than that.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gramsPerStrength ... weight a creature can carry without being slowed,
+// for each point of Strength
+const gramsPerStrength = 15000
+
+// Encumbrance ... how weighed down a creature is by what it carries
+type Encumbrance int
+
+const (
+	// Unencumbered ... carrying no more than the capacity of the creature
+	Unencumbered Encumbrance = iota
+
+	// Burdened ... carrying over the capacity; loses every fourth step
+	Burdened
+
+	// Stressed ... carrying over 1.5 times the capacity; loses every
+	// other step
+	Stressed
+
+	// Overloaded ... carrying over twice the capacity; cannot move at all
+	Overloaded
+)
+
+// encumbranceNames ... names of the levels of encumbrance, as shown to the
+// player
+var encumbranceNames = []string{"Unencumbered", "Burdened", "Stressed",
+	"Overloaded"}
+
+// String ... name of the level of encumbrance.
+/*
+ * @return    string    name, e.g. "Burdened"
+ */
+func (e Encumbrance) String() string {
+
+	if e < Unencumbered || int(e) >= len(encumbranceNames) {
+		return "Unknown"
+	}
+
+	return encumbranceNames[e]
+}
+
+// encumbranceFor ... level of encumbrance of a creature carrying the given
+// weight.
+/*
+ * @param     int            weight carried, in grams
+ * @param     int            carrying capacity, in grams
+ *
+ * @return    Encumbrance    level of encumbrance
+ */
+func encumbranceFor(weight, capacity int) Encumbrance {
+
+	switch {
+	case weight > 2*capacity:
+		return Overloaded
+	case 2*weight > 3*capacity:
+		return Stressed
+	case weight > capacity:
+		return Burdened
+	}
+
+	return Unencumbered
+}
+
+// carryingCapacity ... weight the creature can carry without being slowed.
+/*
+ * @return    int    capacity, in grams
+ */
+func (m *Creature) carryingCapacity() int {
+	return int(m.Strength) * gramsPerStrength
+}
+
+// carriedWeight ... weight of everything in the inventory of the creature,
+// plus everything it has equipped.
+/*
+ * @return    int    weight, in grams
+ */
+func (m *Creature) carriedWeight() int {
+
+	weight := 0
+	for _, itm := range m.inventory {
+		weight += itm.weight * itm.quantity
+	}
+	for _, itm := range m.equippedItems() {
+		weight += itm.weight
+	}
+
+	return weight
+}
+
+// Encumbrance ... how weighed down the creature currently is.
+/*
+ * @return    Encumbrance    level of encumbrance
+ */
+func (m *Creature) Encumbrance() Encumbrance {
+	return encumbranceFor(m.carriedWeight(), m.carryingCapacity())
+}
+
+// checkEncumbrance ... tell the player when what they carry, or their
+// Strength, changes how weighed down they are.
+/*
+ * @return    none
+ */
+func (m *Creature) checkEncumbrance() {
+
+	burden := m.Encumbrance()
+	if burden == m.burden {
+		return
+	}
+
+	switch {
+	case burden == Unencumbered:
+		m.notify("Your load no longer weighs you down.")
+	case burden > m.burden:
+		m.notify(fmt.Sprintf("You are %s by your load.",
+			strings.ToLower(burden.String())))
+	default:
+		m.notify(fmt.Sprintf("Your load is lighter; you are now %s.",
+			strings.ToLower(burden.String())))
+	}
+
+	m.burden = burden
+}
+
+// canStep ... whether the creature manages to take a step; encumbered
+// creatures lose some of their steps, and overloaded ones can not move at
+// all.
+/*
+ * @return    bool    whether or not the creature can step
+ */
+func (m *Creature) canStep() bool {
+
+	every := 0
+	switch m.Encumbrance() {
+	case Overloaded:
+		m.notify("You are carrying too much to move.")
+		return false
+	case Stressed:
+		every = 2
+	case Burdened:
+		every = 4
+	default:
+		return true
+	}
+
+	m.staggerCounter++
+	if m.staggerCounter%every != 0 {
+		return true
+	}
+
+	// The step is lost, but not the time spent trying to take it.
+	m.notify("You stagger under the weight of your load.")
+	m.spend(actionMove)
+	return false
+}
+
+// weightSummary ... summary of the weight carried by the creature, for the
+// inventory screen, e.g. "Weight 162/150kg Burdened".
+/*
+ * @return    string    weight carried, capacity and encumbrance
+ */
+func (m *Creature) weightSummary() string {
+
+	summary := fmt.Sprintf("Weight %d/%dkg", m.carriedWeight()/1000,
+		m.carryingCapacity()/1000)
+
+	if burden := m.Encumbrance(); burden != Unencumbered {
+		summary += " " + burden.String()
+	}
+
+	return summary
+}
@@ -7,22 +7,12 @@
 package main
 
 import (
-	"encoding/gob"
 	"fmt"
-	"math/rand"
-	"os"
-	"strconv"
-	"time"
-
-	"github.com/rbisewski/gocurses"
 )
 
 // log ... Holds the part of the window where in-game messages are shown.
 type log struct {
 
-	// Section of the window where messages are displayed
-	pad *gocurses.Window
-
 	// Line where the messages are added.
 	line int
 
@@ -30,18 +20,15 @@ type log struct {
 	dline int
 }
 
-// GamePad ... gameplay screen section where the level information is rendered
-var GamePad *gocurses.Window
+// Display ... output backend that every draw call goes thru
+var Display Renderer = &CursesRenderer{}
 
-// debugWindow ... window for debug information
-var debugWindow *gocurses.Window
+// Keyboard ... input backend that GetInput reads keys from
+var Keyboard InputSource = &CursesInput{}
 
 // MessageLog ... in-game message log
 var MessageLog log
 
-// StatsWindow ... player stats section
-var StatsWindow *gocurses.Window
-
 // ConsoleHeight ... global to handle console height
 var ConsoleHeight int
 
@@ -66,76 +53,19 @@ var WorldWidth int
  */
 func Init() {
 
-	// Setup the screen.
-	gocurses.Initscr()
-
-	// Send the terminal a 'break'
-	gocurses.Cbreak()
-
-	// Tell console that this isn't going to send data back to bash, etc.
-	gocurses.Noecho()
-
-	// Tell console this'll be using the keypad.
-	gocurses.Stdscr.Keypad(true)
-
-	// Nullify the curses set.
-	gocurses.CursSet(0)
-
-	// No colours? Then give up here via panic()
-	if !gocurses.HasColors() {
-		panic("Panic: Console does not use colours!")
-	}
-
-	// Since we otherwise have colours, go ahead and just run it.
-	gocurses.StartColor()
-
-	// Initialize the colours from the ncurses definitions.
-	InitColours()
-
-	// Figure out the limits of the provided console.
-	ConsoleHeight, ConsoleWidth = gocurses.Getmaxyx()
+	// Setup the output device, and figure out the limits of the provided
+	// console.
+	ConsoleHeight, ConsoleWidth = Display.Start()
 
 	// Carve out a section for the gamepad viewscreen.
 	ScreenHeight, ScreenWidth = Percent(85, ConsoleHeight),
 		Percent(70, ConsoleWidth)
 
-	// Carve out another section for the stats viewscreen.
-	StatsWindow = gocurses.NewWindow(ScreenHeight,
-		ConsoleWidth-ScreenWidth,
-		0,
-		ScreenWidth+1)
-
-	// Assign some space for the debug message section.
-	debugWindow = gocurses.NewWindow(5,
-		ConsoleWidth,
-		ConsoleHeight-1,
-		1)
-
-	// Need in-game messages for those times when the player runs into the
-	// wall or kills a monster, and the like...
-	MessageLog.pad = gocurses.NewPad(100, ScreenWidth)
-
-	// When the game starts, generate a seed from the nanosecond time.
-	rand.Seed(time.Now().UnixNano())
-}
-
-// InitColours ... Function to initialize the colours needed by gocurses.
-/*
- * @return   none
- */
-func InitColours() {
-
-	// Initialize a red-black colour pair (for corpses, etc)
-	gocurses.InitPair(1, gocurses.COLOR_RED, gocurses.COLOR_BLACK)
-
-	// Initialize a yellow-black colour pair (for walls, etc)
-	gocurses.InitPair(2, gocurses.COLOR_YELLOW, gocurses.COLOR_BLACK)
-
-	// Initialize a magenta-black colour pair (for items, etc)
-	gocurses.InitPair(3, gocurses.COLOR_MAGENTA, gocurses.COLOR_BLACK)
+	// Carve out the stats, debug and message log sections.
+	Display.Layout(ScreenHeight, ScreenWidth, ConsoleHeight, ConsoleWidth)
 }
 
-// SetPad ... sets GamePad / WH-WW info to current area in the game object
+// SetPad ... sets game pad / WH-WW info to current area in the game object
 /*
  * @param     int     height
  * @param     int     width
@@ -150,8 +80,7 @@ func SetPad(h, w int) bool {
 	}
 
 	// Initialize a new game pad based on the provided height / width.
-	GamePad = gocurses.NewPad(h, w)
-	if GamePad == nil {
+	if !Display.NewPad(h, w) {
 		DebugLog(&G, fmt.Sprintf("SetPad() --> invalid input"))
 		return false
 	}
@@ -167,7 +96,7 @@ func SetPad(h, w int) bool {
  * @return    none
  */
 func End() {
-	gocurses.End()
+	Display.End()
 }
 
 // Clear ... send the clear() ncurse to this game.
@@ -175,7 +104,7 @@ func End() {
  * @return    none
  */
 func Clear() {
-	gocurses.Clear()
+	Display.Clear()
 }
 
 // Draw ... function to draw a rune at a given (x,y) point.
@@ -189,7 +118,7 @@ func Clear() {
 func Draw(y, x int, ch rune) {
 
 	// Draw the aforementioned character.
-	GamePad.Mvaddch(int(y), int(x), ch)
+	Display.DrawPad(y, x, ch, 0)
 }
 
 // DrawColours ... draw a given ASCII character, with the defined colour.
@@ -203,17 +132,12 @@ func Draw(y, x int, ch rune) {
  */
 func DrawColours(y, x int, ch rune, col int) {
 
-	// Apply a colour filter to the character drawing.
-	GamePad.Attron(gocurses.ColorPair(col))
-
-	// Add the character to the specific location.
-	GamePad.Mvaddch(int(y), int(x), ch)
-
-	// Revert the given filter back to the original console colours afterwards.
-	GamePad.Attroff(gocurses.ColorPair(col))
+	// Add the character to the specific location, using the colour pair.
+	Display.DrawPad(y, x, ch, col)
 }
 
-// DrawMap ... given an Area object, attempt to draw a game level.
+// DrawMap ... given an Area object, attempt to draw the parts of a game
+// level that are in view or remembered by the player.
 /*
  * @param     Area*    pointer to an Area object
  *
@@ -232,6 +156,19 @@ func DrawMap(a *Area) bool {
 		// Cycle thru all of the elements via width...
 		for x := 0; x < a.Width; x++ {
 
+			// Tiles that have never been seen are left blank.
+			if !a.IsExplored(y, x) {
+				Draw(y, x, ' ')
+				continue
+			}
+
+			// Tiles that were seen earlier, but are not currently in
+			// view, are drawn dimmed from memory.
+			if !a.IsVisible(y, x) {
+				DrawColours(y, x, a.Tiles[x+y*a.Width].Ch, 4)
+				continue
+			}
+
 			// Draw the walls in a brownish / yellow colour.
 			if a.Tiles[x+y*a.Width].Ch == '#' {
 				DrawColours(y, x, a.Tiles[x+y*a.Width].Ch, 2)
@@ -274,10 +211,10 @@ func RefreshPad(y int, x int) {
 	}
 
 	// Refresh the output given to the stdout pointer.
-	GamePad.PnoutRefresh(fromY, fromX, 0, 0, ScreenHeight-1, ScreenWidth-1)
+	Display.RefreshPad(fromY, fromX, ScreenHeight, ScreenWidth)
 }
 
-// Write ... send the necessary ASCII characters into console via gocurses.
+// Write ... send the necessary ASCII characters into console via the renderer.
 /*
  * @param     int       y-value
  * @param     int       x-value
@@ -286,7 +223,7 @@ func RefreshPad(y int, x int) {
  * @return    none
  */
 func Write(y int, x int, s string) {
-	gocurses.Mvaddstr(y, x, s)
+	Display.Write(y, x, s)
 }
 
 // DebugLog ... function to write output messages to the debug viewscreen.
@@ -301,14 +238,8 @@ func DebugLog(g *Game, s string) {
 		return
 	}
 
-	// Add some " " buffers to the character pad.
-	debugWindow.Mvaddstr(0, 0, "                         ")
-
-	// Add the given output message.
-	debugWindow.Mvaddstr(0, 0, s)
-
-	// Refresh
-	debugWindow.NoutRefresh()
+	// Replace the debug line with the given output message.
+	Display.WriteDebug(s)
 }
 
 // log ... function to write data to the in-game log screen
@@ -345,15 +276,11 @@ func (l *log) log(s string) {
 	}
 
 	// Format and write the string.
-	l.pad.Mvaddstr(l.line,
-		0,
-		fmt.Sprintf("%s", s))
+	Display.WriteLog(l.line, fmt.Sprintf("%s", s))
 
 	// Refresh the screen to account for the newly added log message.
-	l.pad.PnoutRefresh(l.dline,
-		0,
+	Display.RefreshLog(l.dline,
 		ScreenHeight+1,
-		0,
 		ConsoleHeight-2,
 		ConsoleWidth)
 
@@ -385,31 +312,81 @@ func (p *Creature) UpdateStats() {
 	}
 
 	// Print out the name of the player character.
-	StatsWindow.Mvaddstr(1, 0, fmt.Sprintf("%s", p.name))
+	Display.WriteStats(1, 0, fmt.Sprintf("%s", p.name))
 
-	// Print out the class of the character.
-	StatsWindow.Mvaddstr(3, 0, fmt.Sprintf("%s", p.class.Name))
+	// Print out the level and class of the character.
+	Display.WriteStats(3, 0, fmt.Sprintf("Level %d %s    ", p.Level,
+		p.class.Name))
 
 	// Format and write the HP row in the Stats viewscreen.
 	//
 	// NOTE: several whitespaces were added here to ensure ncurses properly
 	//       wipes away and remaining ASCII data from long hitpoints, etc
 	//
-	StatsWindow.Mvaddstr(5, 0, fmt.Sprintf("HP: %d / %d    ", p.Hp, p.MaxHp))
+	Display.WriteStats(4, 0, fmt.Sprintf("HP: %d / %d    ", p.Hp, p.MaxHp))
+
+	// Only spellcasters have any mana.
+	if maxMana := p.maxMana(); maxMana > 0 {
+		Display.WriteStats(5, 0, fmt.Sprintf("Mana: %d / %d    ", p.Mana,
+			maxMana))
+	}
+
+	// Print out the experience of the player, and how much is needed for
+	// the next level.
+	if p.Level < MaxLevel {
+		Display.WriteStats(6, 0, fmt.Sprintf("XP: %d / %d    ", p.Experience,
+			ExperienceForLevel(p.Level+1)))
+	} else {
+		Display.WriteStats(6, 0, fmt.Sprintf("XP: %d         ", p.Experience))
+	}
+
+	// Print out the attack and defence, including any equipped items.
+	Display.WriteStats(14, 0, fmt.Sprintf("Attack:  %d   ", p.attackValue()))
+	Display.WriteStats(15, 0, fmt.Sprintf("Defence: %d   ", p.defenceValue()))
 
 	// Print out the four primary attributes; strength, intelligence,
 	// agility, and wisdom.
-	StatsWindow.Mvaddstr(7, 0, fmt.Sprintf("Strength:     %d ",
+	Display.WriteStats(7, 0, fmt.Sprintf("Strength:     %d ",
 		p.Strength))
-	StatsWindow.Mvaddstr(8, 0, fmt.Sprintf("Intelligence: %d ",
+	Display.WriteStats(8, 0, fmt.Sprintf("Intelligence: %d ",
 		p.Intelligence))
-	StatsWindow.Mvaddstr(9, 0, fmt.Sprintf("Agility:      %d ",
+	Display.WriteStats(9, 0, fmt.Sprintf("Agility:      %d ",
 		p.Agility))
-	StatsWindow.Mvaddstr(10, 0, fmt.Sprintf("Wisdom:       %d ",
+	Display.WriteStats(10, 0, fmt.Sprintf("Wisdom:       %d ",
 		p.Wisdom))
 
+	// Print out any status effects, and the turns they have left.
+	Display.WriteStats(11, 0, fmt.Sprintf("%-*s", ConsoleWidth-ScreenWidth-1,
+		truncateString(p.statusSummary(), ConsoleWidth-ScreenWidth-1)))
+
+	// Print out how deep in the dungeon the player character is.
+	if p.area != nil {
+		Display.WriteStats(12, 0, fmt.Sprintf("Depth: %d    ", p.area.Depth))
+	}
+
+	// Warn the player when what they carry is slowing them down.
+	burden := ""
+	if b := p.Encumbrance(); b != Unencumbered {
+		burden = b.String()
+	}
+	Display.WriteStats(13, 0, fmt.Sprintf("%-12s", burden))
+
+	// Print out the abilities of the class, and whether they are ready or
+	// how much mana they cost.
+	for i, ab := range p.abilities() {
+		Display.WriteStats(17+i, 0, fmt.Sprintf("%-13s %-8s", ab.Name,
+			p.abilityStatus(ab)))
+	}
+
+	// Print out how much gold the player has.
+	Display.WriteStats(16, 0, fmt.Sprintf("Gold: %d    ", p.Gold))
+
+	// Print out the seed of the game, so that it can be quoted in bug
+	// reports.
+	Display.WriteStats(19, 0, fmt.Sprintf("Seed: %d", G.Seed))
+
 	// Refresh the screen.
-	StatsWindow.NoutRefresh()
+	Display.RefreshStats()
 }
 
 // GetInput ... Grab the keyboard input and then pass back a string.
@@ -417,8 +394,14 @@ func (p *Creature) UpdateStats() {
  * @return    string    Keyboard ASCII character input (Getch() = get character)
  */
 func GetInput() string {
-	gocurses.Doupdate()
-	return string(gocurses.Getch())
+
+	Display.Update()
+
+	// Waiting on the player is when a signal may save the game.
+	gameLock.Unlock()
+	defer gameLock.Lock()
+
+	return Keyboard.GetKey()
 }
 
 // Confirm ... Display a message asking end-user for y/N confirmation.
@@ -463,611 +446,381 @@ func Confirm(msg string) bool {
 	return false
 }
 
-// PickupGroundItem ... pickup an item from the list of ground items
+// ChooseAttribute ... Ask the player which attribute to raise on reaching
+// a new level.
 /*
- * @param     Game*    pointer to the current game object
- * @param     string   the given key that was pressed
+ * @param     Creature*    pointer to the player
  *
- * @return    error    error message, if any
+ * @return    string       name of the chosen attribute, e.g. "agility"
  */
-func PickupGroundItem(g *Game, keyPressed string) error {
-
-	if g == nil || len(keyPressed) < 1 {
-		return fmt.Errorf("PickupGroundItem() --> invalid input")
-	}
+func ChooseAttribute(p *Creature) string {
 
-	var givenItem *Item
+	labels := []string{"Strength", "Intelligence", "Agility", "Wisdom"}
 
-	// If there is less than 1 item, go back.
-	if len(g.GroundItems) < 1 {
-		return nil
+	entries := make([]ListEntry, 0, len(attributeNames))
+	for i, name := range attributeNames {
+		entries = append(entries, ListEntry{Label: fmt.Sprintf("%-12s %3d",
+			labels[i], p.attributeValue(name))})
 	}
 
-	// Do a switch to check if a key between 1-6 was pressed, and
-	// grab that item.
-	switch keyPressed {
-
-	// Number 1
-	case "31":
-
-		// Safety check, ensure there is at least 1 item.
-		if len(g.GroundItems) < 1 {
-			return nil
-		}
-
-		// Grab the 1st item.
-		givenItem = g.GroundItems[0]
-
-	// Number 2
-	case "32":
+	view := NewListView("Raise which attribute?", false)
+	view.SetEntries(entries)
+	view.Draw()
 
-		// Safety check, ensure there is at least 2 items.
-		if len(g.GroundItems) < 2 {
-			return nil
+	// Keep asking until one of the listed letters is pressed.
+	for {
+		if choice, ok := view.Selected(GetInput()); ok {
+			Clear()
+			return attributeNames[choice]
 		}
+	}
+}
 
-		// Add code to grab the 2nd item.
-		givenItem = g.GroundItems[1]
-
-	// Number 3
-	case "33":
-
-		// Safety check, ensure there is at least 3 items.
-		if len(g.GroundItems) < 3 {
-			return nil
-		}
-
-		// Add code to grab the 3rd item.
-		givenItem = g.GroundItems[2]
-
-	// Number 4
-	case "34":
-
-		// Safety check, ensure there is at least 4 items.
-		if len(g.GroundItems) < 4 {
-			return nil
-		}
+// ChooseAbility ... Ask the player which of their abilities to use.
+/*
+ * @param     Creature*    pointer to the player
+ * @param     Ability[]    abilities of the player
+ *
+ * @return    Ability      the chosen ability
+ *            bool         false if the player backed out with Esc
+ */
+func ChooseAbility(p *Creature, abilities []Ability) (Ability, bool) {
 
-		// Add code to grab the 4th item.
-		givenItem = g.GroundItems[3]
+	entries := make([]ListEntry, 0, len(abilities))
+	for _, ab := range abilities {
+		entries = append(entries, ListEntry{Label: fmt.Sprintf("%-14s %7s",
+			ab.Name, p.abilityStatus(ab))})
+	}
 
-	// Number 5
-	case "35":
+	view := NewListView("Use which ability?", false)
+	view.Footer = []string{"Esc) Cancel"}
+	view.SetEntries(entries)
+	view.Draw()
 
-		// Safety check, ensure there is at least 5 items.
-		if len(g.GroundItems) < 5 {
-			return nil
+	// Keep asking until one of the listed letters, or Esc, is pressed.
+	for {
+		key := GetInput()
+		if fmt.Sprintf("%x", key) == "1b" {
+			Clear()
+			return Ability{}, false
 		}
 
-		// Add code to grab the 5th item.
-		givenItem = g.GroundItems[4]
-
-	// Number 6
-	case "36":
-
-		// Safety check, ensure there is at least 6 items.
-		if len(g.GroundItems) < 6 {
-			return nil
+		if choice, ok := view.Selected(key); ok {
+			Clear()
+			return abilities[choice], true
 		}
-
-		// Add code to grab the 6th item.
-		givenItem = g.GroundItems[5]
 	}
+}
 
-	// If the item is nil, then skip this step.
-	if givenItem == nil {
-		return nil
+// ChooseDirection ... Ask the player which way to use an ability, via the
+// numpad or the arrow keys.
+/*
+ * @return    int     y-direction
+ *            int     x-direction
+ *            bool    false if the player backed out with Esc
+ */
+func ChooseDirection() (int, int, bool) {
+
+	MessageLog.log("Which direction? (Esc to cancel)")
+
+	for {
+		switch fmt.Sprintf("%x", GetInput()) {
+		case "38", "c483":
+			return -1, 0, true
+		case "39":
+			return -1, 1, true
+		case "36", "c485":
+			return 0, 1, true
+		case "33":
+			return 1, 1, true
+		case "32", "c482":
+			return 1, 0, true
+		case "31":
+			return 1, -1, true
+		case "34", "c484":
+			return 0, -1, true
+		case "37":
+			return -1, -1, true
+		case "1b":
+			return 0, 0, false
+		}
 	}
+}
 
-	// Set the current area of that item to nil.
-	givenItem.area = nil
-
-	// Attempt to add that item to the player's inventory.
-	g.Player.inventory = append(g.Player.inventory, givenItem)
-
-	// Delete the item from the current area.
-	for index, item := range g.Area.Items {
+// itemEntry ... list entry for an item, with the weight and value of the
+// whole stack.
+/*
+ * @param     Item*        item to list
+ *
+ * @return    ListEntry    entry for the item
+ */
+func itemEntry(itm *Item) ListEntry {
 
-		// if the item matches, remove it
-		if item == givenItem {
+	entry := ListEntry{Label: itm.displayName(), Category: itm.category,
+		Weight: itm.weight * itm.quantity,
+		Value:  itm.priceToSell * itm.quantity}
 
-			// Slick trick --> remove an element from an array while
-			// preserving order.
-			copy(g.Area.Items[index:], g.Area.Items[index+1:])
-			g.Area.Items[len(g.Area.Items)-1] = nil
-			g.Area.Items = g.Area.Items[:len(g.Area.Items)-1]
-			break
-		}
+	// Wares of a shop show what they cost.
+	if itm.unpaid {
+		entry.Label = priceLabel(itm, itm.buyPrice())
 	}
 
-	return nil
+	return entry
 }
 
-// DrawGroundItemsUI ... display the items currently present on the ground.
+// listView ... the list drawn by one of the item screens, which keeps its
+// page, sort and filter for as long as the game runs.
 /*
- * @param     Game*    pointer to the current game object
- * @param     string   key pressed, as a string
+ * @param     string       name of the screen
+ * @param     bool         whether or not the list can be sorted and filtered
  *
- * @return    none
+ * @return    ListView*    list of the screen
  */
-func DrawGroundItemsUI(g *Game, key string) {
+func (g *Game) listView(screen string, sortable bool) *ListView {
 
-	if g == nil || g.Player == nil || len(key) < 1 {
-		return
+	if g.listViews == nil {
+		g.listViews = make(map[string]*ListView)
 	}
 
-	var GuiHeight = 0
-	var GuiWidth = 30
-	var GuiTopBottom = "+"
-	var GuiLeftRight = "|"
-	var GuiLines = make([]string, 0)
+	view, exists := g.listViews[screen]
+	if !exists {
+		view = NewListView("", sortable)
+		g.listViews[screen] = view
+	}
 
-	// Obtain the (x,y) coord of where the player character is currently
-	// standing.
-	locX := g.Player.X
-	locY := g.Player.Y
+	return view
+}
 
-	// Remake the ground items array.
-	g.GroundItems = make([]*Item, 0)
+// groundItemsView ... gather the items on the ground beneath the player
+// into the list of the ground items screen.
+/*
+ * @param     Game*        pointer to the current game object
+ *
+ * @return    ListView*    list of the items on the ground
+ */
+func groundItemsView(g *Game) *ListView {
 
-	// Grab the list of items from the Area te player is currently in and
-	// see if they are present in the same coord.
+	// Remake the ground items array from the items at the (x,y) coord of
+	// the player.
+	g.GroundItems = make([]*Item, 0)
 	for _, itm := range g.Area.Items {
-
-		// If the item is at Player (x,y) position, add it to the list of
-		// items present on the ground; i.e. itemsAtCurrentCoord
-		if locX == itm.X && locY == itm.Y {
+		if itm.X == g.Player.X && itm.Y == g.Player.Y {
 			g.GroundItems = append(g.GroundItems, itm)
 		}
 	}
 
-	// Assemble the various parts of the GUI.
-	for i := 0; i < GuiWidth; i++ {
-		GuiTopBottom += "-"
-		GuiLeftRight += " "
+	entries := make([]ListEntry, 0, len(g.GroundItems))
+	marked := 0
+	for _, itm := range g.GroundItems {
+		entry := itemEntry(itm)
+		entry.Marked = g.groundMarked[itm]
+		if entry.Marked {
+			marked++
+		}
+		entries = append(entries, entry)
 	}
-	GuiTopBottom += "+"
-	GuiLeftRight += "|"
 
-	// Assemble the top element of the inventory screen that displays the
-	// words "Items on the Ground" surrounded by '-' characters.
-	GuiLines = append(GuiLines, GuiTopBottom)
-	GuiLines = append(GuiLines, GuiLeftRight)
-	GuiLines = append(GuiLines,
-		"| "+AlignAndSpaceString("Items on the Ground", "centre", 9)+" |")
-	GuiLines = append(GuiLines, GuiLeftRight)
-	GuiLines = append(GuiLines, GuiTopBottom)
-
-	// Variable to store the current page.
-	currentPage := 1
-	numOfPages := int(len(g.GroundItems) / 6)
-	itemPrintedCounter := 0
-
-	// Generate a ncurses UI here based on the number of items on the
-	// ground; display the items of the relevant inventory page.
-	//
-	// TODO: add logic to increment the current page
-	//
-	for i, itm := range g.GroundItems {
+	// Show what will be picked up before the player confirms it.
+	preview := "Nothing marked yet"
+	if marked > 0 {
+		preview = fmt.Sprintf("Enter takes %d marked", marked)
+	}
 
-		// Skip elements of a forward or backward page.
-		if i < ((currentPage - 1) * 6) {
-			continue
-		}
+	view := g.listView("ground_items", true)
+	view.Title, view.Empty = "Items on the Ground", "No items are here."
+	view.Letters = itemScreenLetters
+	view.SetEntries(entries)
+	view.Footer = []string{view.LetterHint() + " mark, A mark all", preview}
 
-		// Append the item with spacing
-		GuiLines = append(GuiLines, GuiLeftRight)
-		GuiLines = append(GuiLines,
-			"| "+AlignAndSpaceString(strconv.Itoa(i+1)+") "+
-				itm.name, "right", GuiWidth-2)+" |")
+	return view
+}
 
-		// Increment the current number of items printed
-		itemPrintedCounter++
+// inventoryView ... gather the backpack of the player into the list of
+// the inventory screen, titled for what the player is doing with it.
+/*
+ * @param     Game*        pointer to the current game object
+ *
+ * @return    ListView*    list of the items in the backpack
+ */
+func inventoryView(g *Game) *ListView {
+
+	entries := make([]ListEntry, 0, len(g.Player.inventory))
+	for _, itm := range g.Player.inventory {
+		entries = append(entries, itemEntry(itm))
+	}
+
+	view := g.listView("inventory", true)
+	view.Empty = "Backpack is empty."
+	view.Letters = itemScreenLetters
+	view.SetEntries(entries)
+	letters := view.LetterHint()
+	switch g.state {
+	case "use_item":
+		view.Title = "Use which item?"
+		view.Footer = []string{letters + " use, Esc back"}
+	case "drop_item":
+		view.Title = "Drop which item?"
+		view.Footer = []string{letters + " drop, Esc back"}
+	default:
+		view.Title = "Inventory"
+		view.Footer = []string{letters + " equip", "U use, D drop",
+			g.Player.weightSummary()}
+	}
+
+	return view
+}
 
-		// If there are 6 or more items, create a pagination to allow the
-		// end-user to cycle thru all of the items on the ground
-		if itemPrintedCounter >= 6 && numOfPages > 1 {
+// equipmentView ... gather what the player is wearing and holding into the
+// list of the equipment screen, one entry per slot.
+/*
+ * @param     Game*        pointer to the current game object
+ *
+ * @return    ListView*    list of the equipment slots
+ */
+func equipmentView(g *Game) *ListView {
 
-			// assemble the text for the 'Page x of y' label
-			pageLabel := "Page " + strconv.Itoa(currentPage) + " of " +
-				strconv.Itoa(numOfPages)
+	labels := []string{"Head", "Neck", "Torso", "R. Hand", "L. Hand",
+		"Pants"}
 
-			// append it to the bottom of the page
-			GuiLines = append(GuiLines, GuiLeftRight)
-			GuiLines = append(GuiLines,
-				"| "+AlignAndSpaceString(pageLabel, "right",
-					GuiWidth-2)+" |")
-			GuiLines = append(GuiLines, GuiLeftRight)
+	entries := make([]ListEntry, 0, len(equipmentSlotNames))
+	for i, name := range equipmentSlotNames {
 
-			// end the loop since this will only render 6
-			break
+		worn := "nothing"
+		if g.Player.equipment != nil {
+			if itm := *g.Player.equipment.slot(name); itm != nil {
+				worn = itm.displayName()
+			}
 		}
-	}
-
-	// If there are no items on the ground, display a small message
-	// stating that there are no items here.
-	if len(g.GroundItems) < 1 {
-		GuiLines = append(GuiLines, GuiLeftRight)
-		GuiLines = append(GuiLines,
-			"| "+AlignAndSpaceString("No items are here.", "centre", 10)+" |")
-		GuiLines = append(GuiLines, GuiLeftRight)
-	}
-
-	// Get the current number of lines and store it as the height of the UI.
-	GuiHeight = len(GuiLines)
-
-	// While the UI height is less than 17, keep appending |_| lines.
-	for GuiHeight != 17 {
-		GuiLines = append(GuiLines, GuiLeftRight)
-		GuiHeight = len(GuiLines)
-	}
 
-	// Assemble the bottom portion of the ground items UI.
-	GuiLines = append(GuiLines, GuiLeftRight)
-	GuiLines = append(GuiLines, GuiTopBottom)
-
-	// Using the calculated height, go ahead and determine the upper bounds
-	// of the ground items interface, as it relates to the currently drawn
-	// ncurses window.
-	offset := int(GuiHeight/2) + 1
-
-	// Safety check, this shouldn't happen but to safe-guard console offsets,
-	// if the calculated height is less than one or the offset is zero, tell
-	// the developer what happened and leave this function.
-	if GuiHeight < 1 || offset == 0 {
-		DebugLog(&G, "DrawGroundItemsUI() --> improper height and offset, "+
-			"terminating function")
-		return
+		entries = append(entries, ListEntry{
+			Label: fmt.Sprintf("%-8s %s", labels[i]+":", worn)})
 	}
 
-	// Write the ground item UI to the screen.
-	for _, line := range GuiLines {
+	view := g.listView("equipment", false)
+	view.Title = "Equipped Items"
+	view.Letters = itemScreenLetters
+	view.SetEntries(entries)
+	view.Footer = []string{view.LetterHint() + " take off"}
 
-		// Write the given line to the console output.
-		Write((ScreenHeight/2)-offset, ScreenWidth/2, line)
-
-		// Decrement the offset.
-		offset--
-	}
-
-	// Leave this function, since this needs to redraw the UI if the player
-	// picks up all of the items on the ground, etc.
-	return
+	return view
 }
 
-// DrawInventoryUI ... display the inventory the character currently
-// has in their backpack.
+// PickupGroundItem ... mark items from the list of ground items, and then
+// pick up the marked ones
 /*
  * @param     Game*    pointer to the current game object
- * @param     string   key pressed, as a string
+ * @param     string   the given key that was pressed; the letter beside
+ *                     an item marks it, A marks every item, and Enter
+ *                     picks them up
  *
- * @return    none
+ * @return    error    error message, if any
  */
-func DrawInventoryUI(g *Game, key string) {
+func PickupGroundItem(g *Game, keyPressed string) error {
 
-	if g == nil || g.Player == nil || len(key) < 1 {
-		return
+	if g == nil || g.Player == nil || len(keyPressed) < 1 {
+		return fmt.Errorf("PickupGroundItem() --> invalid input")
 	}
 
-	var GuiHeight = 0
-	var GuiWidth = 30
-	var GuiTopBottom = "+"
-	var GuiLeftRight = "|"
-	var GuiLines = make([]string, 0)
-
-	// Obtain the (x,y) coord of where the player character is currently
-	// standing.
-	locX := g.Player.X
-	locY := g.Player.Y
-
-	// Remake the ground items array.
-	g.GroundItems = make([]*Item, 0)
+	view := groundItemsView(g)
 
-	// Grab the list of items from the Area te player is currently in and
-	// see if they are present in the same coord. This will be useful for
-	// when the player wants to drop an item.
-	for _, itm := range g.Area.Items {
-
-		// If the item is at Player (x,y) position, add it to the list of
-		// items present on the ground; i.e. itemsAtCurrentCoord
-		if locX == itm.X && locY == itm.Y {
-			g.GroundItems = append(g.GroundItems, itm)
-		}
+	// If there is less than 1 item, go back.
+	if len(g.GroundItems) < 1 {
+		return nil
 	}
 
-	// Assemble the various parts of the GUI.
-	for i := 0; i < GuiWidth; i++ {
-		GuiTopBottom += "-"
-		GuiLeftRight += " "
+	if g.groundMarked == nil {
+		g.groundMarked = make(map[*Item]bool)
 	}
-	GuiTopBottom += "+"
-	GuiLeftRight += "|"
 
-	// Assemble the inventory screen header.
-	GuiLines = append(GuiLines, GuiTopBottom)
-	GuiLines = append(GuiLines, GuiLeftRight)
-	GuiLines = append(GuiLines,
-		"| "+AlignAndSpaceString("Inventory", "centre", 19)+" |")
-	GuiLines = append(GuiLines, GuiLeftRight)
-	GuiLines = append(GuiLines, GuiTopBottom)
-
-	// Variable to store the current page.
-	currentPage := 1
-	numOfPages := int(len(g.Player.inventory) / 6)
-	itemPrintedCounter := 0
-
-	// Generate a ncurses UI here based on the number of items on the
-	// ground; display the items of the relevant inventory page.
-	//
-	// TODO: add logic to increment the current page
-	//
-	for i, itm := range g.Player.inventory {
+	switch keyPressed {
 
-		// Skip elements of a forward or backward page.
-		if i < ((currentPage - 1) * 6) {
-			continue
+	// A --> mark every item, or clear the marks if all are marked.
+	case "A":
+		markAll := len(g.groundMarked) < len(g.GroundItems)
+		g.groundMarked = make(map[*Item]bool)
+		if markAll {
+			for _, itm := range g.GroundItems {
+				g.groundMarked[itm] = true
+			}
 		}
+		return nil
 
-		// Append the item with spacing
-		GuiLines = append(GuiLines, GuiLeftRight)
-		GuiLines = append(GuiLines,
-			"| "+AlignAndSpaceString(strconv.Itoa(i+1)+") "+
-				itm.name, "right", GuiWidth-2)+" |")
-
-		// Increment the current number of items printed
-		itemPrintedCounter++
-
-		// If there are 6 or more items, create a pagination to allow the
-		// end-user to cycle thru all of the items on the ground
-		if itemPrintedCounter >= 6 && numOfPages > 1 {
-
-			// assemble the text for the 'Page x of y' label
-			pageLabel := "Page " + strconv.Itoa(currentPage) + " of " +
-				strconv.Itoa(numOfPages)
-
-			// append it to the bottom of the page
-			GuiLines = append(GuiLines, GuiLeftRight)
-			GuiLines = append(GuiLines,
-				"| "+AlignAndSpaceString(pageLabel, "right",
-					GuiWidth-2)+" |")
-			GuiLines = append(GuiLines, GuiLeftRight)
+	// Enter --> pick up every marked item.
+	case "\n", "\r":
+		for _, itm := range g.GroundItems {
+			if !g.groundMarked[itm] {
+				continue
+			}
 
-			// end the loop since this will only render 7
-			break
+			g.Area.removeItem(itm)
+			g.Player.addToInventory(itm)
+			g.Player.spend(actionPickUp)
+			MessageLog.log(fmt.Sprintf("You pick up the %s.",
+				itm.displayName()))
 		}
+		g.groundMarked = make(map[*Item]bool)
+		return nil
 	}
 
-	// If the player is currently holding no items, go ahead and mention
-	// that the backpack of the player is empty.
-	if len(g.Player.inventory) < 1 {
-		GuiLines = append(GuiLines, GuiLeftRight)
-		GuiLines = append(GuiLines,
-			"| "+AlignAndSpaceString("Backpack is empty.", "centre", 10)+" |")
-		GuiLines = append(GuiLines, GuiLeftRight)
-	}
-
-	// Get the current number of lines and store it as the height of the UI.
-	GuiHeight = len(GuiLines)
-
-	// While the UI height is less than 17, keep appending |_| lines.
-	for GuiHeight < 17 {
-		GuiLines = append(GuiLines, GuiLeftRight)
-		GuiHeight = len(GuiLines)
+	// Letters mark or unmark the item they are shown beside.
+	index, ok := view.Selected(keyPressed)
+	if !ok {
+		return nil
 	}
 
-	// Assemble the bottom portion of the ground items UI.
-	GuiLines = append(GuiLines, GuiLeftRight)
-	GuiLines = append(GuiLines, GuiTopBottom)
-
-	// Using the calculated height, go ahead and determine the upper bounds
-	// of the ground items interface, as it relates to the currently drawn
-	// ncurses window.
-	offset := int(GuiHeight/2) + 1
-
-	// Safety check, this shouldn't happen but to safe-guard console offsets,
-	// if the calculated height is less than one or the offset is zero, tell
-	// the developer what happened and leave this function.
-	if GuiHeight < 1 || offset == 0 {
-		DebugLog(&G, "DrawInventoryUI() --> improper height and offset, "+
-			"terminating function")
-		return
+	itm := g.GroundItems[index]
+	if g.groundMarked[itm] {
+		delete(g.groundMarked, itm)
+	} else {
+		g.groundMarked[itm] = true
 	}
 
-	// Write the ground item UI to the screen.
-	for _, line := range GuiLines {
-		Write((ScreenHeight/2)-offset, ScreenWidth/2, line)
-		offset--
-	}
+	return nil
 }
 
-// DrawEquipmentUI ... display the equipment the character currently is
-// wearing and what items they are holding.
+// DrawGroundItemsUI ... display the items currently present on the ground.
 /*
  * @param     Game*    pointer to the current game object
- * @param     string   key pressed, as a string
  *
  * @return    none
  */
-func DrawEquipmentUI(g *Game, key string) {
-
-	if g == nil || g.Player == nil || len(key) < 1 {
-		return
-	}
-
-	var GuiHeight = 0
-	var GuiWidth = 30
-	var GuiTopBottom = "+"
-	var GuiLeftRight = "|"
-	var GuiLines = make([]string, 0)
-	var offset = 0
-	var HeadItem = "nothing"
-	var NeckItem = "nothing"
-	var TorsoItem = "nothing"
-	var RHandItem = "nothing"
-	var LHandItem = "nothing"
-	var PantsItem = "nothing"
-
-	// If the player has equipment, go ahead a grab the name of the item.
-	if g.Player.Head != nil {
-		HeadItem = g.Player.Head.name
-	}
-	if g.Player.Neck != nil {
-		NeckItem = g.Player.Neck.name
-	}
-	if g.Player.Torso != nil {
-		TorsoItem = g.Player.Torso.name
-	}
-	if g.Player.RightHand != nil {
-		RHandItem = g.Player.RightHand.name
-	}
-	if g.Player.LeftHand != nil {
-		LHandItem = g.Player.LeftHand.name
-	}
-	if g.Player.Pants != nil {
-		PantsItem = g.Player.Pants.name
-	}
+func DrawGroundItemsUI(g *Game) {
 
-	// Assemble the various parts of the GUI.
-	for i := 0; i < GuiWidth; i++ {
-		GuiTopBottom += "-"
-		GuiLeftRight += " "
-	}
-	GuiTopBottom += "+"
-	GuiLeftRight += "|"
-
-	// Assemble the top element of the inventory screen that displays the
-	// words "Equipped Items" surrounded by '-' characters.
-	GuiLines = append(GuiLines, GuiTopBottom)
-	GuiLines = append(GuiLines, GuiLeftRight)
-	GuiLines = append(GuiLines,
-		"| "+AlignAndSpaceString("Equipped Items", "centre", 14)+" |")
-	GuiLines = append(GuiLines, GuiLeftRight)
-	GuiLines = append(GuiLines, GuiTopBottom)
-	GuiLines = append(GuiLines, GuiLeftRight)
-
-	// Assemble the portion of the inventory screen for the head item.
-	GuiLines = append(GuiLines,
-		"| Head       --> "+AlignAndSpaceString(HeadItem, "right", 13)+" |")
-
-	// Add a spacer.
-	GuiLines = append(GuiLines, GuiLeftRight)
-
-	// Assemble the portion of the inventory screen for the neck item.
-	GuiLines = append(GuiLines,
-		"| Neck       --> "+AlignAndSpaceString(NeckItem, "right", 13)+" |")
-
-	// Add a spacer.
-	GuiLines = append(GuiLines, GuiLeftRight)
-
-	// Assemble the portion of the inventory screen for the torso item.
-	GuiLines = append(GuiLines,
-		"| Torso      --> "+AlignAndSpaceString(TorsoItem, "right", 13)+" |")
-
-	// Add a spacer.
-	GuiLines = append(GuiLines, GuiLeftRight)
-
-	// Assemble the portion of the inventory screen for the right hand item.
-	GuiLines = append(GuiLines,
-		"| Right Hand --> "+AlignAndSpaceString(RHandItem, "right", 13)+" |")
-
-	// Add a spacer.
-	GuiLines = append(GuiLines, GuiLeftRight)
-
-	// Assemble the portion of the inventory screen for the left hand item.
-	GuiLines = append(GuiLines,
-		"| Left Hand  --> "+AlignAndSpaceString(LHandItem, "right", 13)+" |")
-
-	// Add a spacer.
-	GuiLines = append(GuiLines, GuiLeftRight)
-
-	// Assemble the portion of the inventory screen for the pants item.
-	GuiLines = append(GuiLines,
-		"| Pants      --> "+AlignAndSpaceString(PantsItem, "right", 13)+" |")
-
-	// Assemble the bottom portion of the inventory.
-	GuiLines = append(GuiLines, GuiLeftRight)
-	GuiLines = append(GuiLines, GuiTopBottom)
-
-	// Get the current number of lines and store it as the height of the UI.
-	GuiHeight = len(GuiLines)
-
-	// Using the calculated height, go ahead and determine the upper bounds
-	// of the inventory interface, as it relates to the currently drawn
-	// ncurses window.
-	offset = int(GuiHeight / 2)
-
-	// Safety check, this shouldn't happen but to safe-guard console offsets,
-	// if the calculated height is less than one or the offset is zero, tell
-	// the developer what happened and leave this function.
-	if GuiHeight < 1 || offset == 0 {
-		DebugLog(&G, "ToggleInventoryUI() --> improper height and offset, "+
-			"terminating function")
+	if g == nil || g.Player == nil {
 		return
 	}
 
-	// Write the character equipment/inventory screen.
-	for _, line := range GuiLines {
-		Write((ScreenHeight/2)-offset, ScreenWidth/2, line)
-		offset--
-	}
+	groundItemsView(g).Draw()
 }
 
-// SaveGame ... Handles a "save game to disk" event.
+// DrawInventoryUI ... display the inventory the character currently
+// has in their backpack.
 /*
- * @param     Game*    pointer to the current game instance.
+ * @param     Game*    pointer to the current game object
  *
  * @return    none
  */
-func (g *Game) SaveGame() {
+func DrawInventoryUI(g *Game) {
 
-	// Attempt to open the saved game.
-	file, err := os.OpenFile("player.sav", os.O_WRONLY|os.O_CREATE, 0600)
-	if err != nil {
-		panic(err)
+	if g == nil || g.Player == nil {
+		return
 	}
 
-	defer func() {
-		if err := file.Close(); err != nil {
-			panic(err)
-		}
-	}()
-
-	// Attempt to prepare to encode the save game file.
-	encoder := gob.NewEncoder(file)
-	err = encoder.Encode(g)
-	if err != nil {
-		panic(err)
-	}
+	inventoryView(g).Draw()
 }
 
-// LoadGame ... handles a "load game from disk" event.
+// DrawEquipmentUI ... display the equipment the character currently is
+// wearing and what items they are holding.
 /*
- * @param     Game*    pointer to the current game instance.
+ * @param     Game*    pointer to the current game object
  *
- * @return    bool     whether or not the load was successful
+ * @return    none
  */
-func (g *Game) LoadGame(filename string) bool {
-
-	if filename == "" {
-		return false
-	}
-
-	file, err := os.OpenFile(filename, os.O_RDONLY, 0600)
-	if err != nil {
-		panic(err.Error())
-	}
+func DrawEquipmentUI(g *Game) {
 
-	// Prepare to decode the file in question.
-	decoder := gob.NewDecoder(file)
-	err = decoder.Decode(g)
-	if err != nil {
-		panic(err)
-	}
-
-	err = file.Close()
-	if err != nil {
-		panic(err)
+	if g == nil || g.Player == nil {
+		return
 	}
 
-	return true
+	equipmentView(g).Draw()
 }
@@ -0,0 +1,301 @@
+/*
+ * File: equipment.go
+ *
+ * Description: Handles moving items between the inventory of a creature
+ *              and its equipment slots.
+ */
+
+package main
+
+import "fmt"
+
+// equipmentSlotNames ... the slots, in the order they are listed in the
+// equipment screen.
+var equipmentSlotNames = []string{"Head", "Neck", "Torso", "RightHand",
+	"LeftHand", "Pants"}
+
+// slotsForCategory ... which slots an item category may be equipped to,
+// in order of preference.
+/*
+ * @param     string      item category
+ *
+ * @return    string[]    list of slot names, or nil if none
+ */
+func slotsForCategory(category string) []string {
+
+	switch category {
+	case "helmet":
+		return []string{"Head"}
+	case "necklace":
+		return []string{"Neck"}
+	case "armour":
+		return []string{"Torso"}
+	case "pants":
+		return []string{"Pants"}
+	case "blade", "blunt":
+		return []string{"RightHand", "LeftHand"}
+	case "shield":
+		return []string{"LeftHand", "RightHand"}
+	}
+
+	return nil
+}
+
+// slot ... grab a pointer to the equipment slot with the given name.
+/*
+ * @param     string    slot name
+ *
+ * @return    Item**    pointer to the slot, or nil if no such slot
+ */
+func (e *equipment) slot(name string) **Item {
+
+	if e == nil {
+		return nil
+	}
+
+	switch name {
+	case "Head":
+		return &e.Head
+	case "Neck":
+		return &e.Neck
+	case "Torso":
+		return &e.Torso
+	case "RightHand":
+		return &e.RightHand
+	case "LeftHand":
+		return &e.LeftHand
+	case "Pants":
+		return &e.Pants
+	}
+
+	return nil
+}
+
+// equippedItems ... list every item currently equipped by the creature.
+/*
+ * @return    Item[]    equipped items, in slot order
+ */
+func (m *Creature) equippedItems() []*Item {
+
+	items := make([]*Item, 0)
+	if m == nil || m.equipment == nil {
+		return items
+	}
+
+	for _, name := range equipmentSlotNames {
+		if itm := *m.equipment.slot(name); itm != nil {
+			items = append(items, itm)
+		}
+	}
+
+	return items
+}
+
+// attackValue ... attack of the creature, including its equipment.
+/*
+ * @return    int    base attack plus equipped item bonuses
+ */
+func (m *Creature) attackValue() int {
+
+	value := m.Att
+	for _, itm := range m.equippedItems() {
+		if !itm.isBroken {
+			value += itm.attackIncrease
+		}
+	}
+
+	return value
+}
+
+// defenceValue ... defence of the creature, including its equipment.
+/*
+ * @return    int    base defence plus equipped item bonuses
+ */
+func (m *Creature) defenceValue() int {
+
+	value := m.Def
+	for _, itm := range m.equippedItems() {
+		if !itm.isBroken {
+			value += itm.defenceIncrease
+		}
+	}
+
+	return value
+}
+
+// equip ... move an item from the inventory into a matching slot; if
+// every matching slot is taken, the item in the first one is swapped back
+// into the inventory.
+/*
+ * @param     Item*    item from the inventory of the creature
+ *
+ * @return    bool     whether or not the item was equipped
+ */
+func (m *Creature) equip(itm *Item) bool {
+
+	if m == nil || itm == nil {
+		DebugLog(&G, "equip() --> invalid input")
+		return false
+	}
+
+	if m.equipment == nil {
+		DebugLog(&G, fmt.Sprintf("equip() --> the %s cannot wear equipment",
+			m.name))
+		return false
+	}
+
+	// Broken items cannot be worn or wielded.
+	if itm.isBroken {
+		m.notify(fmt.Sprintf("The %s is broken and cannot be equipped.",
+			itm.name))
+		return false
+	}
+
+	// Shop wares have to be bought before they can be worn, or they would
+	// leave the shop without ever being noticed.
+	if itm.unpaid {
+		m.notify(fmt.Sprintf("You must pay for the %s first.", itm.name))
+		return false
+	}
+
+	slots := slotsForCategory(itm.category)
+	if !itm.canEquip || len(slots) < 1 {
+		m.notify(fmt.Sprintf("The %s cannot be equipped.", itm.name))
+		return false
+	}
+
+	// Take the item out of the inventory.
+	if !m.removeFromInventory(itm) {
+		DebugLog(&G, "equip() --> item is not in the inventory")
+		return false
+	}
+
+	// Prefer an empty slot, otherwise swap out the first one.
+	target := m.equipment.slot(slots[0])
+	for _, name := range slots {
+		if s := m.equipment.slot(name); *s == nil {
+			target = s
+			break
+		}
+	}
+
+	if *target != nil {
+		m.inventory = append(m.inventory, *target)
+		m.notify(fmt.Sprintf("You take off the %s.", (*target).name))
+	}
+
+	*target = itm
+	m.notify(fmt.Sprintf("You equip the %s.", itm.name))
+
+	return true
+}
+
+// unequip ... move the item in the given slot back into the inventory.
+/*
+ * @param     string    slot name
+ *
+ * @return    bool      whether or not an item was taken off
+ */
+func (m *Creature) unequip(name string) bool {
+
+	if m == nil || m.equipment == nil {
+		DebugLog(&G, "unequip() --> invalid input")
+		return false
+	}
+
+	s := m.equipment.slot(name)
+	if s == nil || *s == nil {
+		return false
+	}
+
+	m.inventory = append(m.inventory, *s)
+	m.notify(fmt.Sprintf("You take off the %s.", (*s).name))
+	*s = nil
+
+	return true
+}
+
+// removeFromInventory ... take an item out of the inventory, preserving
+// the order of the remaining items.
+/*
+ * @param     Item*    item to remove
+ *
+ * @return    bool     whether or not the item was found
+ */
+func (m *Creature) removeFromInventory(itm *Item) bool {
+
+	for i, held := range m.inventory {
+		if held == itm {
+			m.inventory = append(m.inventory[:i], m.inventory[i+1:]...)
+			m.checkEncumbrance()
+			return true
+		}
+	}
+
+	return false
+}
+
+// notify ... write a message to the in-game log, but only if the creature
+// is the player character.
+/*
+ * @param     string    message
+ *
+ * @return    none
+ */
+func (m *Creature) notify(msg string) {
+	if m != nil && m.species == "player" {
+		MessageLog.log(msg)
+	}
+}
+
+// EquipInventoryItem ... equip an item from the inventory screen
+/*
+ * @param     Game*    pointer to the current game object
+ * @param     string   the given key that was pressed
+ *
+ * @return    error    error message, if any
+ */
+func EquipInventoryItem(g *Game, key string) error {
+
+	if g == nil || g.Player == nil || len(key) < 1 {
+		return fmt.Errorf("EquipInventoryItem() --> invalid input")
+	}
+
+	// Only the letters beside the listed items select one.
+	index, ok := inventoryView(g).Selected(key)
+	if !ok {
+		return nil
+	}
+
+	if g.Player.equip(g.Player.inventory[index]) {
+		g.Player.spend(actionEquip)
+	}
+
+	return nil
+}
+
+// UnequipSlot ... take off an item from the equipment screen
+/*
+ * @param     Game*    pointer to the current game object
+ * @param     string   the given key that was pressed
+ *
+ * @return    error    error message, if any
+ */
+func UnequipSlot(g *Game, key string) error {
+
+	if g == nil || g.Player == nil || len(key) < 1 {
+		return fmt.Errorf("UnequipSlot() --> invalid input")
+	}
+
+	// Letters a-f select the slot, in the order shown on the screen.
+	index, ok := equipmentView(g).Selected(key)
+	if !ok {
+		return nil
+	}
+
+	if g.Player.unequip(equipmentSlotNames[index]) {
+		g.Player.spend(actionEquip)
+	}
+
+	return nil
+}
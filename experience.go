@@ -0,0 +1,130 @@
+/*
+ * File: experience.go
+ *
+ * Description: Awards experience to the player for kills, and raises the
+ *              level of the player once enough has been gained.
+ */
+
+package main
+
+import (
+	"fmt"
+)
+
+// MaxLevel ... highest level a character can reach
+const MaxLevel = 20
+
+// Hit points gained on every new level.
+const levelHpGain = 5
+
+// attributeNames ... the four primary attributes, in the order they are
+// listed on screen
+var attributeNames = []string{"strength", "intelligence", "agility",
+	"wisdom"}
+
+// ExperienceForLevel ... total experience needed to reach a level; each
+// level takes 50 more than the one before, i.e. 50, 150, 300, ...
+/*
+ * @param     int    level
+ *
+ * @return    int    experience needed
+ */
+func ExperienceForLevel(level int) int {
+
+	if level < 2 {
+		return 0
+	}
+
+	return 25 * level * (level - 1)
+}
+
+// gainExperience ... award experience to the player, going up a level
+// for every threshold that is passed.
+/*
+ * @param     int    experience gained
+ *
+ * @return    none
+ */
+func (m *Creature) gainExperience(xp int) {
+
+	if m == nil || xp < 1 || m.species != "player" {
+		return
+	}
+
+	m.Experience += xp
+	MessageLog.log(fmt.Sprintf("You gain %d experience.", xp))
+
+	for m.Level < MaxLevel && m.Experience >= ExperienceForLevel(m.Level+1) {
+		m.levelUp()
+	}
+}
+
+// levelUp ... raise the level of the player by one; this adds hit points,
+// a point to the essential attribute of their class, and a point to an
+// attribute of their choice.
+/*
+ * @return    none
+ */
+func (m *Creature) levelUp() {
+
+	m.Level++
+	m.MaxHp += levelHpGain
+	m.Hp += levelHpGain
+
+	if m.class != nil {
+		m.raiseAttribute(m.class.EssentialAttribute)
+	}
+
+	MessageLog.log(fmt.Sprintf("You have reached level %d!", m.Level))
+	m.UpdateStats()
+
+	chosen := ChooseAttribute(m)
+	m.raiseAttribute(chosen)
+	MessageLog.log(fmt.Sprintf("Your %s increases.", chosen))
+
+	// A stronger player can carry more.
+	m.checkEncumbrance()
+	m.UpdateStats()
+}
+
+// raiseAttribute ... add a point to one of the primary attributes.
+/*
+ * @param     string    name of the attribute, e.g. "strength"
+ *
+ * @return    none
+ */
+func (m *Creature) raiseAttribute(name string) {
+
+	switch name {
+	case "strength":
+		m.Strength++
+	case "intelligence":
+		m.Intelligence++
+	case "agility":
+		m.Agility++
+	case "wisdom":
+		m.Wisdom++
+	}
+}
+
+// attributeValue ... current value of one of the primary attributes.
+/*
+ * @param     string    name of the attribute, e.g. "strength"
+ *
+ * @return    uint      value of the attribute
+ */
+func (m *Creature) attributeValue(name string) uint {
+
+	switch name {
+	case "strength":
+		return m.Strength
+	case "intelligence":
+		return m.Intelligence
+	case "agility":
+		return m.Agility
+	case "wisdom":
+		return m.Wisdom
+	}
+
+	return 0
+}
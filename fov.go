@@ -0,0 +1,205 @@
+/*
+ * File: fov.go
+ *
+ * Description: Field of view calculations, via recursive shadowcasting
+ *              over the BlockSight property of the area tiles.
+ */
+
+package main
+
+import "fmt"
+
+// SightRadius ... how many tiles away the player character can see.
+const SightRadius = 10
+
+// octantTransforms ... multipliers that map the first octant onto each of
+// the eight octants surrounding the viewer; in the form xx, xy, yx, yy.
+var octantTransforms = [8][4]int{
+	{1, 0, 0, 1},
+	{0, 1, 1, 0},
+	{0, -1, 1, 0},
+	{-1, 0, 0, 1},
+	{-1, 0, 0, -1},
+	{0, -1, -1, 0},
+	{0, 1, -1, 0},
+	{1, 0, 0, -1},
+}
+
+// computeFOV ... determine which tiles can be seen from a given (x,y)
+/*
+ * @param     int     y-value of the viewer
+ * @param     int     x-value of the viewer
+ * @param     int     sight radius
+ *
+ * @return    bool    whether or not the field of view was computed
+ */
+func (a *Area) computeFOV(y, x, radius int) bool {
+
+	if a == nil || radius < 1 {
+		DebugLog(&G, fmt.Sprintf("computeFOV() --> invalid input"))
+		return false
+	}
+
+	// Areas loaded from older save files may lack the explored tile
+	// array, so allocate it here if needed.
+	if len(a.Explored) != len(a.Tiles) {
+		a.Explored = make([]bool, len(a.Tiles))
+	}
+
+	// Start with a clean slate of visible tiles.
+	a.visible = make([]bool, len(a.Tiles))
+
+	// The viewer can always see the tile they are standing on.
+	a.markVisible(y, x)
+
+	// Cast light into each of the eight octants around the viewer.
+	for _, t := range octantTransforms {
+		a.castLight(y, x, 1, 1.0, 0.0, radius, t[0], t[1], t[2], t[3])
+	}
+
+	return true
+}
+
+// castLight ... recursively scan an octant row by row, shrinking the
+// lit slope range whenever a tile that blocks sight is encountered.
+/*
+ * @param     int        y-value of the viewer
+ * @param     int        x-value of the viewer
+ * @param     int        row (distance from the viewer) to start at
+ * @param     float64    starting slope of the lit range
+ * @param     float64    ending slope of the lit range
+ * @param     int        sight radius
+ * @param     int...     octant transform multipliers
+ *
+ * @return    none
+ */
+func (a *Area) castLight(cy, cx, row int, start, end float64, radius,
+	xx, xy, yx, yy int) {
+
+	if start < end {
+		return
+	}
+
+	radiusSquared := radius * radius
+	newStart := 0.0
+
+	for j := row; j <= radius; j++ {
+
+		dx, dy := -j-1, -j
+		blocked := false
+
+		for dx <= 0 {
+			dx++
+
+			// Translate the relative coords into area coords.
+			x := cx + dx*xx + dy*xy
+			y := cy + dx*yx + dy*yy
+
+			// Slopes of the left and right edges of this tile.
+			leftSlope := (float64(dx) - 0.5) / (float64(dy) + 0.5)
+			rightSlope := (float64(dx) + 0.5) / (float64(dy) - 0.5)
+
+			if start < rightSlope {
+				continue
+			} else if end > leftSlope {
+				break
+			}
+
+			// Light the tile if it is within the sight radius.
+			if dx*dx+dy*dy < radiusSquared {
+				a.markVisible(y, x)
+			}
+
+			// Previous tile was a blocker, so keep narrowing the range
+			// until a see-thru tile is found.
+			if blocked {
+				if a.blocksSight(y, x) {
+					newStart = rightSlope
+					continue
+				}
+				blocked = false
+				start = newStart
+				continue
+			}
+
+			// Hit a blocker, so scan the next row with the narrowed range.
+			if a.blocksSight(y, x) && j < radius {
+				blocked = true
+				a.castLight(cy, cx, j+1, start, leftSlope, radius,
+					xx, xy, yx, yy)
+				newStart = rightSlope
+			}
+		}
+
+		if blocked {
+			break
+		}
+	}
+}
+
+// blocksSight ... whether the tile at (x,y) blocks the line of sight.
+/*
+ * @param     int     y-value
+ * @param     int     x-value
+ *
+ * @return    bool    true if blocking or out of bounds
+ */
+func (a *Area) blocksSight(y, x int) bool {
+
+	if y < 0 || y >= a.Height || x < 0 || x >= a.Width {
+		return true
+	}
+
+	return a.Tiles[x+y*a.Width].BlockSight
+}
+
+// markVisible ... flag the tile at (x,y) as both visible and explored.
+/*
+ * @param     int     y-value
+ * @param     int     x-value
+ *
+ * @return    none
+ */
+func (a *Area) markVisible(y, x int) {
+
+	if y < 0 || y >= a.Height || x < 0 || x >= a.Width {
+		return
+	}
+
+	a.visible[x+y*a.Width] = true
+	a.Explored[x+y*a.Width] = true
+}
+
+// IsVisible ... whether the tile at (x,y) is currently in view.
+/*
+ * @param     int     y-value
+ * @param     int     x-value
+ *
+ * @return    bool    true if the tile was lit by the last computeFOV()
+ */
+func (a *Area) IsVisible(y, x int) bool {
+
+	if a == nil || y < 0 || y >= a.Height || x < 0 || x >= a.Width ||
+		len(a.visible) != len(a.Tiles) {
+		return false
+	}
+
+	return a.visible[x+y*a.Width]
+}
+
+// IsExplored ... whether the tile at (x,y) has ever been seen.
+/*
+ * @param     int     y-value
+ * @param     int     x-value
+ *
+ * @return    bool    true if the tile is remembered
+ */
+func (a *Area) IsExplored(y, x int) bool {
+
+	if a == nil || y < 0 || y >= a.Height || x < 0 || x >= a.Width ||
+		len(a.Explored) != len(a.Tiles) {
+		return false
+	}
+
+	return a.Explored[x+y*a.Width]
+}
@@ -0,0 +1,323 @@
+/*
+ * File: framebuffer.go
+ *
+ * Description: In-memory implementation of the Renderer and InputSource
+ *              interfaces, for running the game without a terminal.
+ */
+
+package main
+
+import "strings"
+
+// Cell ... a single character position of the frame buffer.
+type Cell struct {
+	Ch     rune
+	Colour int
+}
+
+// FrameBuffer ... Renderer that composes the console in memory.
+//
+// Every section of the screen is kept as its own grid of cells, and the
+// refresh calls copy them onto the Screen grid in the same way ncurses
+// copies its windows and pads onto the terminal.
+type FrameBuffer struct {
+
+	// Dimensions of the console.
+	Height int
+	Width  int
+
+	// Composed console output, indexed as [y][x].
+	Screen [][]Cell
+
+	// Number of times Update() has been called.
+	Frames int
+
+	// Game pad holding the whole area map.
+	pad [][]Cell
+
+	// Message log pad.
+	log [][]Cell
+
+	// Location of the stats window on the console.
+	statsY int
+	statsX int
+
+	// Row of the console where debug output is written.
+	debugY int
+
+	// Text written onto the console since the last Update(); just like
+	// ncurses, it only shows once the console is refreshed, and so ends up
+	// on top of the game pad.
+	pending []screenWrite
+}
+
+// screenWrite ... text waiting to be written onto the console.
+type screenWrite struct {
+	y int
+	x int
+	s string
+}
+
+// KeyQueue ... InputSource that hands out a prepared list of keys.
+//
+// Once every key has been handed out GetKey returns an empty string, so
+// the caller is expected to queue a complete sequence of keys beforehand.
+type KeyQueue struct {
+	keys []string
+}
+
+// NewFrameBuffer ... FrameBuffer constructor.
+/*
+ * @param     int             console height
+ * @param     int             console width
+ *
+ * @return    FrameBuffer*    pointer to a new, blank frame buffer
+ */
+func NewFrameBuffer(h, w int) *FrameBuffer {
+
+	if h < 1 || w < 1 {
+		return nil
+	}
+
+	return &FrameBuffer{Height: h, Width: w, Screen: newCellGrid(h, w)}
+}
+
+// newCellGrid ... allocate a grid of blank cells.
+/*
+ * @param     int        height
+ * @param     int        width
+ *
+ * @return    Cell[][]   grid of space characters
+ */
+func newCellGrid(h, w int) [][]Cell {
+
+	grid := make([][]Cell, h)
+	for y := range grid {
+		grid[y] = make([]Cell, w)
+		for x := range grid[y] {
+			grid[y][x] = Cell{' ', 0}
+		}
+	}
+
+	return grid
+}
+
+// putString ... write a string onto a grid, clipping at the edges.
+/*
+ * @param     Cell[][]   grid to write onto
+ * @param     int        y-value
+ * @param     int        x-value
+ * @param     string     text to write
+ *
+ * @return    none
+ */
+func putString(grid [][]Cell, y, x int, s string) {
+
+	if y < 0 || y >= len(grid) {
+		return
+	}
+
+	for _, ch := range s {
+		if x >= 0 && x < len(grid[y]) {
+			grid[y][x] = Cell{ch, 0}
+		}
+		x++
+	}
+}
+
+// Start ... the frame buffer needs no setup, so return its size.
+func (fb *FrameBuffer) Start() (int, int) {
+	return fb.Height, fb.Width
+}
+
+// Layout ... record where the stats, debug and log sections live.
+/*
+ * @param     int    gamepad viewscreen height
+ * @param     int    gamepad viewscreen width
+ * @param     int    console height
+ * @param     int    console width
+ *
+ * @return    none
+ */
+func (fb *FrameBuffer) Layout(screenHeight, screenWidth, consoleHeight,
+	consoleWidth int) {
+
+	fb.statsY = 0
+	fb.statsX = screenWidth + 1
+	fb.debugY = consoleHeight - 1
+	fb.log = newCellGrid(100, screenWidth)
+}
+
+// End ... nothing to restore for an in-memory buffer.
+func (fb *FrameBuffer) End() {}
+
+// Clear ... wipe the composed console.
+func (fb *FrameBuffer) Clear() {
+	fb.Screen = newCellGrid(fb.Height, fb.Width)
+	fb.pending = nil
+}
+
+// NewPad ... allocate a new game pad.
+func (fb *FrameBuffer) NewPad(h, w int) bool {
+
+	if h < 1 || w < 1 {
+		return false
+	}
+
+	fb.pad = newCellGrid(h, w)
+	return true
+}
+
+// DrawPad ... draw a rune onto the game pad.
+func (fb *FrameBuffer) DrawPad(y, x int, ch rune, colour int) {
+
+	if y < 0 || y >= len(fb.pad) || x < 0 || x >= len(fb.pad[y]) {
+		return
+	}
+
+	fb.pad[y][x] = Cell{ch, colour}
+}
+
+// RefreshPad ... copy a section of the game pad onto the console.
+/*
+ * @param     int    y-value of the top-left corner of the pad section
+ * @param     int    x-value of the top-left corner of the pad section
+ * @param     int    height of the section
+ * @param     int    width of the section
+ *
+ * @return    none
+ */
+func (fb *FrameBuffer) RefreshPad(fromY, fromX, height, width int) {
+
+	for y := 0; y < height && y < fb.Height; y++ {
+
+		// Skip the rows that lie outside of the pad.
+		if fromY+y < 0 || fromY+y >= len(fb.pad) {
+			continue
+		}
+
+		for x := 0; x < width && x < fb.Width; x++ {
+
+			// Skip the columns that lie outside of the pad.
+			if fromX+x < 0 || fromX+x >= len(fb.pad[fromY+y]) {
+				continue
+			}
+
+			fb.Screen[y][x] = fb.pad[fromY+y][fromX+x]
+		}
+	}
+}
+
+// Write ... write a string onto the console, once it is next updated.
+func (fb *FrameBuffer) Write(y, x int, s string) {
+	fb.pending = append(fb.pending, screenWrite{y, x, s})
+}
+
+// WriteStats ... write a string onto the stats section of the console.
+func (fb *FrameBuffer) WriteStats(y, x int, s string) {
+	putString(fb.Screen, fb.statsY+y, fb.statsX+x, s)
+}
+
+// RefreshStats ... the stats are written straight to the console.
+func (fb *FrameBuffer) RefreshStats() {}
+
+// WriteLog ... write a string onto a line of the message log pad.
+func (fb *FrameBuffer) WriteLog(line int, s string) {
+	putString(fb.log, line, 0, s)
+}
+
+// RefreshLog ... copy a section of the message log onto the console.
+/*
+ * @param     int    first line of the log pad to display
+ * @param     int    console row where the log begins
+ * @param     int    console row where the log ends
+ * @param     int    console width
+ *
+ * @return    none
+ */
+func (fb *FrameBuffer) RefreshLog(fromLine, top, bottom, width int) {
+
+	for y := top; y <= bottom && y < fb.Height; y++ {
+
+		line := fromLine + (y - top)
+		if y < 0 || line < 0 || line >= len(fb.log) {
+			continue
+		}
+
+		for x := 0; x < width && x < fb.Width && x < len(fb.log[line]); x++ {
+			fb.Screen[y][x] = fb.log[line][x]
+		}
+	}
+}
+
+// WriteDebug ... replace the contents of the debug line.
+func (fb *FrameBuffer) WriteDebug(s string) {
+	putString(fb.Screen, fb.debugY, 1, strings.Repeat(" ", fb.Width))
+	putString(fb.Screen, fb.debugY, 1, s)
+}
+
+// Update ... write out any text waiting for the console, and count the
+// frame.
+func (fb *FrameBuffer) Update() {
+
+	for _, w := range fb.pending {
+		putString(fb.Screen, w.y, w.x, w.s)
+	}
+	fb.pending = nil
+
+	fb.Frames++
+}
+
+// Row ... return a row of the console as plain text.
+/*
+ * @param     int       y-value
+ *
+ * @return    string    characters of the given row
+ */
+func (fb *FrameBuffer) Row(y int) string {
+
+	if y < 0 || y >= len(fb.Screen) {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, c := range fb.Screen[y] {
+		b.WriteRune(c.Ch)
+	}
+
+	return b.String()
+}
+
+// String ... return the whole console as plain text, one row per line.
+func (fb *FrameBuffer) String() string {
+
+	rows := make([]string, len(fb.Screen))
+	for y := range fb.Screen {
+		rows[y] = strings.TrimRight(fb.Row(y), " ")
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// Push ... append keys to the end of the queue.
+func (q *KeyQueue) Push(keys ...string) {
+	q.keys = append(q.keys, keys...)
+}
+
+// Len ... number of keys still waiting in the queue.
+func (q *KeyQueue) Len() int {
+	return len(q.keys)
+}
+
+// GetKey ... hand out the next key in the queue, or "" if it is empty.
+func (q *KeyQueue) GetKey() string {
+
+	if len(q.keys) < 1 {
+		return ""
+	}
+
+	key := q.keys[0]
+	q.keys = q.keys[1:]
+
+	return key
+}
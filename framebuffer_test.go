@@ -0,0 +1,95 @@
+/*
+ * File: framebuffer_test.go
+ *
+ * Description: Drives the game loop without a terminal, by feeding it a
+ *              script of keys and reading back what it drew.
+ */
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// scriptedInput ... InputSource that notes what was on the screen before
+// handing out each key of a KeyQueue, and ends the game once it runs out
+type scriptedInput struct {
+	fb      *FrameBuffer
+	queue   *KeyQueue
+	screens []string
+}
+
+func (s *scriptedInput) GetKey() string {
+
+	// Running out of keys would otherwise wait on the player forever.
+	if s.queue.Len() == 0 {
+		panic(errReplayEnded)
+	}
+
+	s.screens = append(s.screens, s.fb.String())
+
+	return s.queue.GetKey()
+}
+
+func TestHeadlessSession(t *testing.T) {
+
+	// Keep any saves out of the home directory, and leave the game clear
+	// of whatever earlier tests left behind.
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	AutosaveTurns, SeedFlag, PlayerClass = 0, 1234, nil
+	G = Game{}
+
+	if err := LoadTypes(""); err != nil {
+		t.Fatal(err)
+	}
+
+	fb := NewFrameBuffer(50, 160)
+	queue := &KeyQueue{}
+	input := &scriptedInput{fb: fb, queue: queue}
+	Display, Keyboard = fb, input
+
+	// A new warrior called Tess, who opens and closes the inventory, then
+	// quits without saving.
+	queue.Push("N", "T", "e", "s", "s", "\n", "1", "\n")
+	queue.Push("i", "\x1b")
+	queue.Push("Q", "y")
+
+	if err := play(); err != nil {
+		t.Fatalf("play() = %v, with %d keys left", err, queue.Len())
+	}
+
+	if !G.state.Quiting() {
+		t.Errorf("state = %q, want quit", G.state)
+	}
+
+	// The screen as it was before each key was pressed.
+	checks := []struct {
+		key  int
+		want string
+	}{
+		{0, "Press 'N' to start a new game."},
+		{5, "Tess"},
+		{7, "You have selected... Warrior"},
+		{8, "Tess"},
+		{9, "Inventory"},
+		{11, "Quit Without Saving? Y/N"},
+	}
+
+	for _, c := range checks {
+		if !strings.Contains(input.screens[c.key], c.want) {
+			t.Errorf("screen before key %d does not show %q:\n%s", c.key,
+				c.want, input.screens[c.key])
+		}
+	}
+
+	// Closing the inventory takes it off the screen again.
+	if strings.Contains(input.screens[10], "Inventory") {
+		t.Errorf("inventory still shown after Esc:\n%s", input.screens[10])
+	}
+
+	// The player is drawn on the map.
+	if !strings.Contains(input.screens[8], "@") {
+		t.Errorf("player not drawn:\n%s", input.screens[8])
+	}
+}
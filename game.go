@@ -26,11 +26,41 @@ type Game struct {
 	// Pointer to the player-character object.
 	Player *Creature
 
-	// Pointer to area array.
+	// Pointer to area array of the level the player is currently on.
 	Area *Area
 
+	// Every level of the dungeon generated so far.
+	Dungeon *Dungeon
+
+	// Depth of the level the player is currently on.
+	Depth int
+
+	// Seed that the dungeon and every random event is derived from.
+	Seed int64
+
+	// Random number streams for each subsystem.
+	rng *RandomStreams
+
+	// Number of turns that have gone by so far, and the steps taken
+	// towards the next one.
+	Turns int
+	ticks int
+
+	// Save slot the game is saved to, or "" if not yet saved.
+	slot string
+
+	// Turn on which the game was last autosaved.
+	autosavedTurn int
+
 	// List of items on the ground at a give coord
 	GroundItems []*Item
+
+	// Items on the ground that the player has marked to be picked up.
+	groundMarked map[*Item]bool
+
+	// Lists of the item screens, by screen, so that each keeps its page,
+	// sort and filter between visits.
+	listViews map[string]*ListView
 }
 
 // Init ... Function to initialize the game.
@@ -55,11 +85,25 @@ func (g *Game) Init() {
 	// Set the game state.
 	g.state = "menu"
 
+	// A brand new game has taken no turns, and has no save slot yet.
+	g.Turns, g.ticks = 0, 0
+	g.slot = ""
+	g.autosavedTurn = 0
+
 	// Initially the player is not picking up items from thr ground.
 	g.GroundItems = make([]*Item, 0)
+	g.groundMarked = make(map[*Item]bool)
+	g.listViews = make(map[string]*ListView)
+
+	// Seed the random number streams of the game.
+	g.Seed = NewSeed()
+	g.rng = NewRandomStreams(g.Seed)
 
-	// Generate an area map
-	g.Area, y, x = NewArea(240, 250)
+	// Generate the first level of the dungeon.
+	g.Dungeon = NewDungeon(g.Seed)
+	g.Depth = 1
+	g.Area = g.Dungeon.Level(g.Depth, g.rng)
+	y, x = g.Area.UpY, g.Area.UpX
 
 	// Safety check, if the player name is blank, default to anonymous.
 	if len(PlayerName) == 0 {
@@ -77,11 +121,18 @@ func (g *Game) Init() {
 		g.Area, make([]*Item, 0), 30, 30, 10, 5, PlayerClass, 10, 10, 10,
 		10, 10, 0)
 
+	// Spellcasters start out with a full pool of mana.
+	g.Player.Mana = g.Player.maxMana()
+
+	// Every adventurer sets out with a few supplies.
+	for _, key := range []string{"healing_potion", "ration"} {
+		if itm := NewItemFromType(key, y, x, nil); itm != nil {
+			g.Player.addToInventory(itm)
+		}
+	}
+
 	// Attach the player-character creature to the map.
 	g.Area.Creatures = append(g.Area.Creatures, g.Player)
-
-	// Pass along the area, and populate the world with a number of monsters.
-	g.Area.populateAreaWithCreatures()
 }
 
 // Menuing ... Determines if in-menu.
@@ -212,17 +263,28 @@ func (g *Game) Menu() GameState {
 		fallthrough
 	case "L":
 
-		g.Init()
+		// Let the player pick one of the saved games.
+		Clear()
+		slot, chosen := g.LoadMenu()
+		Clear()
+		if !chosen {
+			state = "menu"
+			break
+		}
 
-		// Attempt to load the previous game.
-		if !g.LoadGame("player.sav") {
+		// Attempt to load the chosen game into a game of its own, so
+		// that a failed load leaves this one as it was.
+		loaded := Game{DebugMode: DeveloperMode, state: "menu",
+			listViews: make(map[string]*ListView)}
+		if err := loaded.LoadFromSlot(slot); err != nil {
 
-			MenuErrorMsg = "No recent save game was detected. Please start a new game."
-			DebugLog(g, fmt.Sprintf("Menu() --> unable to load previous game"))
+			MenuErrorMsg = "Unable to load the saved game: " + err.Error()
+			DebugLog(g, fmt.Sprintf("Menu() --> unable to load previous game: %v", err))
 
 			state = "menu"
 			break
 		}
+		*g = loaded
 
 		// Give the main game pad a height and width.
 		SetPad(g.Area.Height, g.Area.Width)
@@ -246,6 +308,101 @@ func (g *Game) Menu() GameState {
 	return state
 }
 
+// LoadMenu ... Write out the load screen, which lists every saved game
+// and allows old ones to be deleted.
+/*
+ * @return    SaveSlot    slot picked by the player
+ *            bool        whether or not a slot was picked
+ */
+func (g *Game) LoadMenu() (SaveSlot, bool) {
+
+	deleting := false
+
+	for true {
+
+		slots, err := ListSaveSlots()
+		if err != nil {
+			MenuErrorMsg = "Unable to list the saved games: " + err.Error()
+			return SaveSlot{}, false
+		}
+
+		if len(slots) == 0 {
+			MenuErrorMsg = "No saved games were detected. Please start a new game."
+			return SaveSlot{}, false
+		}
+
+		top := Percent(25, ConsoleHeight)
+		Write(top, ConsoleWidth/2, "Load a saved game:")
+
+		// Each slot takes up two lines, so list as many as will fit.
+		shown := Max(1, Min(len(slots), 9, (ConsoleHeight-top-6)/2))
+		for i := 0; i < shown; i++ {
+			lines := slots[i].Describe()
+			Write(top+2+i*2, ConsoleWidth/2, strconv.Itoa(i+1)+") "+lines[0])
+			Write(top+3+i*2, ConsoleWidth/2, lines[1])
+		}
+
+		bottom := top + 3 + shown*2
+		if len(slots) > shown {
+			Write(bottom-1, ConsoleWidth/2, fmt.Sprintf("...and %d older saves",
+				len(slots)-shown))
+		}
+
+		keys := "1"
+		if shown > 1 {
+			keys += "-" + strconv.Itoa(shown)
+		}
+
+		if deleting {
+			Write(bottom, ConsoleWidth/2, "Press "+keys+" to delete a save, or [Esc] to cancel.")
+		} else {
+			Write(bottom, ConsoleWidth/2, "Press "+keys+" to load a save, 'D' to delete one,")
+			Write(bottom+1, ConsoleWidth/2, "or [Esc] to go back.")
+		}
+
+		key := GetInput()
+		Clear()
+
+		// Escape cancels a deletion, or otherwise leaves the load screen.
+		if fmt.Sprintf("%x", key) == "1b" {
+			if !deleting {
+				return SaveSlot{}, false
+			}
+			deleting = false
+			continue
+		}
+
+		if key == "d" || key == "D" {
+			deleting = true
+			continue
+		}
+
+		if !IsNumeric(key) {
+			continue
+		}
+
+		num, err := ConvertKeyToNumeric(key)
+		if err != nil || num < 1 || int(num) > shown {
+			continue
+		}
+		slot := slots[num-1]
+
+		if !deleting {
+			return slot, true
+		}
+
+		deleting = false
+		if Confirm("Delete this save? Y/N") {
+			if err := DeleteSaveSlot(slot); err != nil {
+				DebugLog(g, fmt.Sprintf("LoadMenu() --> %v", err))
+			}
+		}
+		Clear()
+	}
+
+	return SaveSlot{}, false
+}
+
 // Death ... handle the event of a PC death (by monsters or the like).
 /*
  * @param      Game    current game instance
@@ -280,6 +437,9 @@ func (s GameState) Quiting() bool {
 // Output ... generate the game screen output.
 func (g *Game) Output() {
 
+	// Work out what the player character can currently see.
+	g.Area.computeFOV(g.Player.Y, g.Player.X, g.Player.sightRadius())
+
 	DrawMap(g.Area)
 
 	// Cycle thru all of the item present in the current area. If an item is
@@ -292,6 +452,11 @@ func (g *Game) Output() {
 			continue
 		}
 
+		// Items outside of the field of view are not drawn.
+		if !g.Area.IsVisible(item.Y, item.X) {
+			continue
+		}
+
 		// If the item is in fact a dead creature, colour it red.
 		if item.category == "corpse" {
 
@@ -316,6 +481,11 @@ func (g *Game) Output() {
 			continue
 		}
 
+		// Creatures outside of the field of view are not drawn.
+		if !g.Area.IsVisible(m.Y, m.X) {
+			continue
+		}
+
 		// Draw a monster at its current coords.
 		Draw(m.Y, m.X, m.ch)
 	}
@@ -339,106 +509,14 @@ func (g *Game) Input() {
 	// Convert the key pressed to a hex string value.
 	keyAsString := fmt.Sprintf("%x", key)
 
-	// If the player presses the ESC key in the inventory or ground items
-	// screen, then switch back to playing mode.
-	if (g.state == "equipment" || g.state == "inventory" ||
-		g.state == "ground_items") && keyAsString == "1b" {
-
-		// Set the state back to playing.
-		g.state = "playing"
-		return
-
-		// Equipment screen is open and the player presses the left arrow.
-	} else if g.state == "equipment" && keyAsString == "c484" {
-
-		// Draw and populate the inventory ncurses UI.
-		g.state = "equipment"
-		DrawEquipmentUI(g, keyAsString)
-		return
-
-		// Equipment screen is open and the player presses the left arrow.
-	} else if g.state == "equipment" && keyAsString == "c485" {
-
-		// Draw and populate the inventory ncurses UI.
-		g.state = "inventory"
-		DrawInventoryUI(g, keyAsString)
-		return
-
-		// Inventory screen is open and the player presses the left arrow.
-	} else if g.state == "inventory" && keyAsString == "c484" {
-
-		// Draw and populate the inventory ncurses UI.
-		g.state = "equipment"
-		DrawEquipmentUI(g, keyAsString)
-		return
-
-		// Inventory screen is open and the player presses the right arrow.
-	} else if g.state == "inventory" && keyAsString == "c485" {
-
-		// Draw the UI and populate the global list of ground items.
-		g.state = "ground_items"
-		DrawGroundItemsUI(g, keyAsString)
-
-		// Leave here since this needs to continue showing the ground
-		// items UI to the player.
-		return
-
-		// Ground items screen is open and the player presses the left arrow.
-	} else if g.state == "ground_items" && keyAsString == "c484" {
-
-		// Draw and populate the inventory ncurses UI.
-		g.state = "inventory"
-		DrawInventoryUI(g, keyAsString)
-		return
-
-		// Ground items screen is open and the player presses the right arrow.
-	} else if g.state == "ground_items" && keyAsString == "c485" {
-
-		// Draw the UI and populate the global list of ground items.
-		DrawGroundItemsUI(g, keyAsString)
-
-		// Leave here since this needs to continue showing the ground
-		// items UI to the player.
-		return
-
-		// If the player character inventory is open, and the key being pressed
-		// is not "e" then do nothing.
-	} else if g.state == "equipment" && keyAsString != "65" {
-
-		// Draw and populate the inventory ncurses UI.
-		DrawEquipmentUI(g, keyAsString)
+	// Keys pressed on the equipment, inventory and ground items screens
+	// are dealt with by those screens.
+	if g.itemScreenInput(key) {
 		return
+	}
 
-		// If the player character inventory is open, and the key being pressed
-		// is not "i" then do nothing.
-	} else if g.state == "inventory" && keyAsString != "69" {
-
-		// Draw and populate the inventory ncurses UI.
-		DrawInventoryUI(g, keyAsString)
-		return
-
-		// If the ground items UI is open, and the key being pressed
-		// is not "g" then do nothing.
-	} else if g.state == "ground_items" && keyAsString != "67" {
-
-		// Draw the UI and populate the global list of ground items.
-		DrawGroundItemsUI(g, keyAsString)
-
-		// Do a check to see if a player presses the key 1-7 then attempt
-		// to add that item to the player's inventory.
-		err := PickupGroundItem(g, keyAsString)
-
-		// If there was an error, print it out.
-		if err != nil {
-			fmt.Printf(err.Error())
-			return
-		}
-
-		// Draw the UI and populate the global list of ground items.
-		DrawGroundItemsUI(g, keyAsString)
-
-		// Leave here since this needs to continue showing the ground
-		// items UI to the player.
+	// Likewise for the shop screen.
+	if g.shopInput(key) {
 		return
 	}
 
@@ -448,62 +526,66 @@ func (g *Game) Input() {
 	// Numpad 8 --> Move player north
 	case "38":
 		g.Player.Move(-1, 0)
-		g.processAI()
+		g.advanceTime()
 
 	// Numpad 9 --> Move player north-east
 	case "39":
 		g.Player.Move(-1, 1)
-		g.processAI()
+		g.advanceTime()
 
 	// Numpad 6 --> Move player east
 	case "36":
 		g.Player.Move(0, 1)
-		g.processAI()
+		g.advanceTime()
 
 	// Numpad 3 --> Move player south-west
 	case "33":
 		g.Player.Move(1, 1)
-		g.processAI()
+		g.advanceTime()
 
 	// Numpad 2 --> Move player south
 	case "32":
 		g.Player.Move(1, 0)
-		g.processAI()
+		g.advanceTime()
 
 	// Numpad 1 --> Move player south-east
 	case "31":
 		g.Player.Move(1, -1)
-		g.processAI()
+		g.advanceTime()
 
 	// Numpad 4 --> Move player west
 	case "34":
 		g.Player.Move(0, -1)
-		g.processAI()
+		g.advanceTime()
 
 	// Numpad 7 --> Move player north-west
 	case "37":
 		g.Player.Move(-1, -1)
-		g.processAI()
+		g.advanceTime()
 
 	// Down Arrow --> Move player south
 	case "c482":
 		g.Player.Move(1, 0)
-		g.processAI()
+		g.advanceTime()
 
 	// Up Arrow --> Move player north
 	case "c483":
 		g.Player.Move(-1, 0)
-		g.processAI()
+		g.advanceTime()
 
 	// Left Arrow --> Move player west
 	case "c484":
 		g.Player.Move(0, -1)
-		g.processAI()
+		g.advanceTime()
 
 	// Right Arrow --> Move player east
 	case "c485":
 		g.Player.Move(0, 1)
-		g.processAI()
+		g.advanceTime()
+
+	// Numpad 5 or . --> Rest for a turn
+	case "35", "2e":
+		g.Rest()
 
 	// e --> Open / close the equipment screen.
 	case "65":
@@ -513,7 +595,7 @@ func (g *Game) Input() {
 
 			// Enable the equipment state and draw the UI
 			g.state = "equipment"
-			DrawEquipmentUI(g, keyAsString)
+			DrawEquipmentUI(g)
 			return
 		}
 
@@ -526,9 +608,11 @@ func (g *Game) Input() {
 		// If the ground items UI screen is not yet open.
 		if g.state != "ground_items" {
 
-			// Enable the inventory state and draw the UI
+			// Enable the inventory state and draw the UI, with nothing
+			// marked to be picked up yet.
 			g.state = "ground_items"
-			DrawGroundItemsUI(g, keyAsString)
+			g.groundMarked = make(map[*Item]bool)
+			DrawGroundItemsUI(g)
 			return
 		}
 
@@ -543,17 +627,42 @@ func (g *Game) Input() {
 
 			// Enable the inventory state and draw the UI
 			g.state = "inventory"
-			DrawInventoryUI(g, keyAsString)
+			DrawInventoryUI(g)
 			return
 		}
 
 		// Otherwise the inventory is open, so flip the state.
 		g.state = "playing"
 
+	// a --> Use a class ability
+	case "61":
+		g.UseAbility()
+
+	// > --> Go down a staircase
+	case "3e":
+		if g.Player.stunned() {
+			g.advanceTime()
+		} else if g.takeStairs(1) {
+			g.Player.spend(actionMove)
+			g.advanceTime()
+		}
+
+	// < --> Go up a staircase
+	case "3c":
+		if g.Player.stunned() {
+			g.advanceTime()
+		} else if g.takeStairs(-1) {
+			g.Player.spend(actionMove)
+			g.advanceTime()
+		}
+
 	// S --> Save game
 	case "53":
 		if Confirm("Save and Quit? Y/N") {
-			g.SaveGame()
+			if err := g.SaveToSlot(); err != nil {
+				MessageLog.log("Unable to save the game: " + err.Error())
+				break
+			}
 			MessageLog.log("Game Saved")
 			g.state = "quit"
 		}
@@ -565,3 +674,158 @@ func (g *Game) Input() {
 		}
 	}
 }
+
+// itemScreens ... the item screens, in the order the left and right arrows
+// move between them
+var itemScreens = []GameState{"equipment", "inventory", "ground_items"}
+
+// itemScreenInput ... handle a key pressed on one of the item screens.
+//
+// Letters pick an entry of the list, except for e, i and g, which close
+// the screen they opened or switch to another; Esc always closes it.
+/*
+ * @param     string    key pressed
+ *
+ * @return    bool      whether or not the key was dealt with
+ */
+func (g *Game) itemScreenInput(key string) bool {
+
+	keyAsString := fmt.Sprintf("%x", key)
+
+	// Picking an item to use or drop is part of the inventory screen.
+	screen := g.state
+	if screen == "use_item" || screen == "drop_item" {
+		screen = "inventory"
+	}
+
+	current := -1
+	for i, name := range itemScreens {
+		if name == screen {
+			current = i
+		}
+	}
+	if current < 0 {
+		return false
+	}
+
+	var view *ListView
+	switch screen {
+	case "equipment":
+		view = equipmentView(g)
+	case "inventory":
+		view = inventoryView(g)
+	default:
+		view = groundItemsView(g)
+	}
+	switch {
+
+	// ESC backs out of picking an item, or closes the screen.
+	case keyAsString == "1b":
+		if g.state == "use_item" || g.state == "drop_item" {
+			g.state = "inventory"
+			break
+		}
+		g.state = "playing"
+		return true
+
+	// Left and right arrows move to the neighbouring screen.
+	case keyAsString == "c484" || keyAsString == "c485":
+		if keyAsString == "c484" && current > 0 {
+			current--
+		} else if keyAsString == "c485" && current < len(itemScreens)-1 {
+			current++
+		}
+		g.state = itemScreens[current]
+
+	// e, i or g closes the screen it opened, or switches to another; none
+	// of them pick an entry on these screens.
+	case keyAsString == "65" || keyAsString == "69" || keyAsString == "67":
+		return false
+
+	// Paging, sorting and filtering.
+	case view.HandleKey(key):
+
+	// A stunned player can still look through the screens, but whatever
+	// they try to wear, use, drop or pick up is left be.
+	case g.itemScreenActs(view, key) && g.Player.stunned():
+		if g.state == "use_item" || g.state == "drop_item" {
+			g.state = "inventory"
+		}
+
+	case g.state == "equipment":
+		if err := UnequipSlot(g, key); err != nil {
+			DebugLog(g, err.Error())
+		}
+
+	// U, Q or R picks an item to use, quaff or read, and D one to drop.
+	case g.state == "inventory" && (key == "U" || key == "Q" || key == "R"):
+		g.state = "use_item"
+
+	case g.state == "inventory" && key == "D":
+		g.state = "drop_item"
+
+	case g.state == "inventory":
+		if err := EquipInventoryItem(g, key); err != nil {
+			DebugLog(g, err.Error())
+		}
+
+	// Using an item takes time.
+	case g.state == "use_item":
+		used, err := UseInventoryItem(g, key)
+		if err != nil {
+			DebugLog(g, err.Error())
+		}
+
+		if used {
+			g.state = "playing"
+			g.Player.spend(actionUseItem)
+			g.advanceTime()
+			return true
+		}
+
+	case g.state == "drop_item":
+		if err := DropInventoryItem(g, key); err != nil {
+			DebugLog(g, err.Error())
+		}
+		g.state = "inventory"
+
+	case g.state == "ground_items":
+		if err := PickupGroundItem(g, key); err != nil {
+			DebugLog(g, err.Error())
+		}
+	}
+
+	// Equipping, dropping and picking up items take time too.
+	g.advanceTime()
+
+	switch g.state {
+	case "equipment":
+		DrawEquipmentUI(g)
+	case "ground_items":
+		DrawGroundItemsUI(g)
+	default:
+		DrawInventoryUI(g)
+	}
+
+	return true
+}
+
+// itemScreenActs ... whether the key pressed on an item screen does
+// something that takes time, rather than just looking through the list.
+/*
+ * @param     ListView*    list of the screen
+ * @param     string       key pressed
+ *
+ * @return    bool         whether or not the key acts on an item
+ */
+func (g *Game) itemScreenActs(view *ListView, key string) bool {
+
+	// Letters only mark ground items; Enter is what picks them up.
+	if g.state == "ground_items" {
+		return (key == "\n" || key == "\r") && len(g.groundMarked) > 0
+	}
+
+	_, picked := view.Selected(key)
+
+	return picked
+}
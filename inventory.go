@@ -0,0 +1,158 @@
+/*
+ * File: inventory.go
+ *
+ * Description: Handles what a creature carries; identical consumables
+ *              share a single stack, and items can be dropped.
+ */
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// stackable ... whether the item may share a stack with identical items;
+// only consumables stack, since equipment wears out individually.
+/*
+ * @return    bool    true if the item stacks
+ */
+func (itm *Item) stackable() bool {
+	return len(itm.effects) > 0
+}
+
+// stacksWith ... whether two items are identical, and so may share a
+// single stack.
+/*
+ * @param     Item*    the other item
+ *
+ * @return    bool     true if both items stack together
+ */
+func (itm *Item) stacksWith(other *Item) bool {
+	return itm.stackable() && other.stackable() && itm.name == other.name &&
+		itm.category == other.category && itm.useVerb == other.useVerb &&
+		itm.unpaid == other.unpaid &&
+		reflect.DeepEqual(itm.effects, other.effects)
+}
+
+// displayName ... name of the item as listed on screen, along with the
+// size of the stack if there is more than one, or whether it is broken.
+/*
+ * @return    string    e.g. "Potion of Healing (3)"
+ */
+func (itm *Item) displayName() string {
+
+	if itm.category == goldCategory {
+		return fmt.Sprintf("%d gold", itm.quantity)
+	}
+
+	if itm.isBroken {
+		return itm.name + " (broken)"
+	}
+
+	if itm.quantity > 1 {
+		return fmt.Sprintf("%s (%d)", itm.name, itm.quantity)
+	}
+
+	return itm.name
+}
+
+// addToInventory ... put an item into the inventory of a creature, onto
+// an existing stack if there is one.
+/*
+ * @param     Item*    item to add
+ *
+ * @return    none
+ */
+func (m *Creature) addToInventory(itm *Item) {
+
+	// Items being carried are not on any level.
+	itm.area = nil
+
+	if itm.quantity < 1 {
+		itm.quantity = 1
+	}
+
+	// Gold goes into the purse, not the backpack.
+	if itm.category == goldCategory {
+		m.Gold += itm.quantity
+		return
+	}
+
+	for _, held := range m.inventory {
+		if held.stacksWith(itm) {
+			held.quantity += itm.quantity
+			m.checkEncumbrance()
+			return
+		}
+	}
+
+	m.inventory = append(m.inventory, itm)
+	m.checkEncumbrance()
+}
+
+// dropItem ... put an item from the inventory onto the ground beneath the
+// creature.
+/*
+ * @param     Item*    item to drop, along with the rest of its stack
+ *
+ * @return    bool     whether or not the item was dropped
+ */
+func (m *Creature) dropItem(itm *Item) bool {
+
+	if !m.removeFromInventory(itm) {
+		return false
+	}
+
+	itm.Y, itm.X, itm.area = m.Y, m.X, m.area
+	m.area.Items = append(m.area.Items, itm)
+
+	m.notify(fmt.Sprintf("You drop the %s.", itm.displayName()))
+
+	return true
+}
+
+// DropInventoryItem ... drop an item from the inventory screen
+/*
+ * @param     Game*    pointer to the current game object
+ * @param     string   the given key that was pressed
+ *
+ * @return    error    error message, if any
+ */
+func DropInventoryItem(g *Game, key string) error {
+
+	if g == nil || g.Player == nil || len(key) < 1 {
+		return fmt.Errorf("DropInventoryItem() --> invalid input")
+	}
+
+	// Only the letters beside the listed items select one.
+	index, ok := inventoryView(g).Selected(key)
+	if !ok {
+		return nil
+	}
+
+	if g.Player.dropItem(g.Player.inventory[index]) {
+		g.Player.spend(actionDrop)
+	}
+
+	return nil
+}
+
+// removeItem ... take an item off the ground of a level, preserving the
+// order of the remaining items.
+/*
+ * @param     Item*    item to remove
+ *
+ * @return    bool     whether or not the item was found
+ */
+func (a *Area) removeItem(itm *Item) bool {
+
+	for i, item := range a.Items {
+		if item == itm {
+			a.Items = append(a.Items[:i], a.Items[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
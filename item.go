@@ -7,7 +7,11 @@
 
 package main
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/rbisewski/go_roguelike/types"
+)
 
 // Item ... Structure to hold the attributes of an item.
 type Item struct {
@@ -50,6 +54,17 @@ type Item struct {
 	// or decreases (if negative) the attack / defence of a creature.
 	attackIncrease  int
 	defenceIncrease int
+
+	// What happens when the item is used up, and the verb used to describe
+	// it, e.g. "quaff"; items with no effects cannot be used.
+	effects []types.ItemEffect
+	useVerb string
+
+	// Number of identical items held in this stack.
+	quantity int
+
+	// Whether the item is a ware of a shop that has yet to be paid for.
+	unpaid bool
 }
 
 // NewItem ... Item constructor function.
@@ -104,7 +119,40 @@ func NewItem(name string,
 		priceToSell,
 		weight,
 		attackIncrease,
-		defenceIncrease}
+		defenceIncrease,
+		nil,
+		"use",
+		1,
+		false}
+}
+
+// NewItemFromType ... create an item from one of the item types.
+/*
+ * @param     string    key of the item type, e.g. "healing_potion"
+ * @param     int       Y
+ * @param     int       X
+ * @param     *Area     pointer to area object that the item is located,
+ *                      if this is `nil` then the item is held by a creature
+ *
+ * @return    Item*     pointer to the new item, or nil if the type is
+ *                      unknown
+ */
+func NewItemFromType(key string, Y int, X int, area *Area) *Item {
+
+	info, exists := GlobalItemTypeInfoMap[key]
+	if !exists {
+		DebugLog(&G, fmt.Sprintf("NewItemFromType() --> unknown item "+
+			"type: %s", key))
+		return nil
+	}
+
+	itm := NewItem(info.Name, info.Category, Y, X, info.Ch, area,
+		info.Can_equip, info.Is_broken, info.Durability_current,
+		info.Durability_maximum, info.Price_to_purchase, info.Price_to_sell,
+		info.Weight, info.Attack_increase, info.Defence_increase)
+	itm.effects, itm.useVerb = info.Effects, info.Use_verb
+
+	return itm
 }
 
 //! Function to handle what occurs when the current durability of an item
@@ -174,6 +222,12 @@ func (itm *Item) adjustDurability(amount int) {
 		// Leave this routine, since the item broken event has been handled.
 		return
 	}
+
+	// If the item was broken but has since been repaired, then it can be
+	// used once again.
+	if itm.isBroken {
+		itm.eventRepaired()
+	}
 }
 
 //! Function to handle what occurs when an item breaks.
@@ -193,3 +247,22 @@ func (itm *Item) eventBroken() {
 	DebugLog(&G, fmt.Sprintf("eventBroken() --> item [%s] is now broken",
 		itm.name))
 }
+
+//! Function to handle what occurs when a broken item is repaired.
+/*
+ * @caller    Item*    the given item which is no longer broken
+ *
+ * @return    none
+ */
+func (itm *Item) eventRepaired() {
+
+	// Allow the item to be equipped again, if it is something that can be
+	// worn or wielded.
+	itm.canEquip = len(slotsForCategory(itm.category)) > 0
+
+	// Clear the isBroken flag.
+	itm.isBroken = false
+
+	DebugLog(&G, fmt.Sprintf("eventRepaired() --> item [%s] is no longer "+
+		"broken", itm.name))
+}
@@ -0,0 +1,398 @@
+/*
+ * File: listview.go
+ *
+ * Description: A boxed list of entries, e.g. items, shown a page at a time
+ *              and picked by letter, which can be sorted and filtered by
+ *              category. Every item screen and menu is drawn with it.
+ */
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// listPageSize ... number of entries shown on each page
+const listPageSize = 8
+
+// listLetters ... letters that pick the entries of a page, in order
+const listLetters = "abcdefgh"
+
+// itemScreenLetters ... letters that pick the entries of the equipment,
+// inventory and ground items screens; e, g and i are left out, since they
+// switch between those screens
+const itemScreenLetters = "abcdfhjk"
+
+// listWidth ... width of the text inside the box
+const listWidth = 28
+
+// listFooterLines ... lines kept for the footer, so that every list box is
+// the same height
+const listFooterLines = 3
+
+// listSorts ... the orders a list can be sorted in, cycled thru with S
+var listSorts = []string{"default", "name", "category", "weight", "value"}
+
+// ListEntry ... a single line of a list.
+type ListEntry struct {
+
+	// Text shown for the entry.
+	Label string
+
+	// What the entry is sorted and filtered by.
+	Category string
+	Weight   int
+	Value    int
+
+	// Whether the entry is starred, e.g. marked to be picked up.
+	Marked bool
+}
+
+// ListView ... Structure to hold a list, and how it is currently paged,
+// sorted and filtered.
+type ListView struct {
+
+	// Heading of the box.
+	Title string
+
+	// Shown in place of the entries when there are none.
+	Empty string
+
+	// Hints at the bottom of the box, e.g. which keys do what.
+	Footer []string
+
+	// Whether S and F sort and filter the list; short lists in a fixed
+	// order, such as the equipment slots, leave this off.
+	Sortable bool
+
+	// Letters that pick the entries of a page, in order; one for each of
+	// the listPageSize entries.
+	Letters string
+
+	entries []ListEntry
+
+	// Positions of the entries that are shown, in the order shown.
+	shown []int
+
+	page   int
+	sortBy int
+	filter string
+}
+
+// NewListView ... ListView constructor function.
+/*
+ * @param     string       heading of the box
+ * @param     bool         whether or not the list can be sorted and filtered
+ *
+ * @return    ListView*    the new list
+ */
+func NewListView(title string, sortable bool) *ListView {
+	return &ListView{Title: title, Sortable: sortable, Letters: listLetters}
+}
+
+// SetEntries ... replace the entries of the list, keeping the page, sort
+// and filter as they were where possible.
+/*
+ * @param     ListEntry[]    entries, in their default order
+ *
+ * @return    none
+ */
+func (v *ListView) SetEntries(entries []ListEntry) {
+
+	v.entries = entries
+
+	// Drop a filter once nothing of that category is left.
+	if v.filter != "" && !v.hasCategory(v.filter) {
+		v.filter = ""
+	}
+
+	v.shown = make([]int, 0, len(entries))
+	for i, entry := range entries {
+		if v.filter == "" || entry.Category == v.filter {
+			v.shown = append(v.shown, i)
+		}
+	}
+
+	sort.SliceStable(v.shown, func(i, j int) bool {
+		return v.less(entries[v.shown[i]], entries[v.shown[j]])
+	})
+
+	if v.page >= v.Pages() {
+		v.page = v.Pages() - 1
+	}
+}
+
+// less ... whether one entry comes before another in the current sort;
+// the heaviest and most valuable entries come first.
+/*
+ * @param     ListEntry    first entry
+ * @param     ListEntry    second entry
+ *
+ * @return    bool         whether the first entry comes first
+ */
+func (v *ListView) less(a, b ListEntry) bool {
+
+	switch listSorts[v.sortBy] {
+	case "name":
+		return a.Label < b.Label
+	case "category":
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		return a.Label < b.Label
+	case "weight":
+		return a.Weight > b.Weight
+	case "value":
+		return a.Value > b.Value
+	}
+
+	return false
+}
+
+// hasCategory ... whether any entry is of the given category.
+/*
+ * @param     string    category
+ *
+ * @return    bool      whether or not an entry has it
+ */
+func (v *ListView) hasCategory(category string) bool {
+
+	for _, entry := range v.entries {
+		if entry.Category == category {
+			return true
+		}
+	}
+
+	return false
+}
+
+// categories ... every category of the entries, in alphabetical order.
+/*
+ * @return    string[]    list of categories
+ */
+func (v *ListView) categories() []string {
+
+	seen := make(map[string]bool)
+	categories := make([]string, 0)
+	for _, entry := range v.entries {
+		if entry.Category != "" && !seen[entry.Category] {
+			seen[entry.Category] = true
+			categories = append(categories, entry.Category)
+		}
+	}
+	sort.Strings(categories)
+
+	return categories
+}
+
+// Pages ... number of pages the shown entries fill; an empty list still
+// has a page.
+/*
+ * @return    int    number of pages
+ */
+func (v *ListView) Pages() int {
+
+	if len(v.shown) == 0 {
+		return 1
+	}
+
+	return (len(v.shown) + listPageSize - 1) / listPageSize
+}
+
+// HandleKey ... page, sort or filter the list: > and < turn the page, S
+// cycles the sort and F cycles the category filter.
+/*
+ * @param     string    key pressed
+ *
+ * @return    bool      whether or not the key changed the list
+ */
+func (v *ListView) HandleKey(key string) bool {
+
+	switch {
+	case key == ">" && v.page < v.Pages()-1:
+		v.page++
+
+	case key == "<" && v.page > 0:
+		v.page--
+
+	case key == "S" && v.Sortable:
+		v.sortBy = (v.sortBy + 1) % len(listSorts)
+		v.SetEntries(v.entries)
+
+	case key == "F" && v.Sortable:
+		// Go from showing everything, thru each category in turn, and
+		// back to everything.
+		categories := v.categories()
+		next := ""
+		for i, category := range categories {
+			if v.filter == "" {
+				next = category
+				break
+			}
+			if category == v.filter && i+1 < len(categories) {
+				next = categories[i+1]
+				break
+			}
+		}
+		v.filter, v.page = next, 0
+		v.SetEntries(v.entries)
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// Selected ... the entry picked by a letter key on the current page.
+/*
+ * @param     string    key pressed
+ *
+ * @return    int       position of the entry, as given to SetEntries
+ *            bool      whether or not the key picks an entry
+ */
+func (v *ListView) Selected(key string) (int, bool) {
+
+	letter := strings.Index(v.Letters, key)
+	if len(key) != 1 || letter < 0 {
+		return 0, false
+	}
+
+	n := v.page*listPageSize + letter
+	if n >= len(v.shown) {
+		return 0, false
+	}
+
+	return v.shown[n], true
+}
+
+// LetterHint ... the letters that pick the entries of the current page,
+// written as briefly as they can be, e.g. "a-d,f" for five entries on an
+// item screen; for footers that say which keys do what.
+/*
+ * @return    string    letters of the current page
+ */
+func (v *ListView) LetterHint() string {
+
+	n := Min(len(v.shown)-v.page*listPageSize, listPageSize, len(v.Letters))
+	letters := v.Letters[:Max(n, 1)]
+
+	// Runs of neighbouring letters are written as a range.
+	parts := make([]string, 0)
+	for i := 0; i < len(letters); {
+		j := i
+		for j+1 < len(letters) && letters[j+1] == letters[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, letters[i:i+1]+"-"+letters[j:j+1])
+		} else {
+			parts = append(parts, letters[i:i+1])
+		}
+		i = j + 1
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// Draw ... draw the current page of the list in a box in the middle of the
+// screen. The box is always the same height, so that it covers whatever
+// was drawn there before.
+/*
+ * @return    none
+ */
+func (v *ListView) Draw() {
+
+	border := "+" + strings.Repeat("-", listWidth+2) + "+"
+	lines := []string{border, "", centreString(v.Title, listWidth), "",
+		border}
+
+	for i := 0; i < listPageSize; i++ {
+
+		n := v.page*listPageSize + i
+		switch {
+		case n < len(v.shown):
+			entry := v.entries[v.shown[n]]
+			mark := ""
+			if entry.Marked {
+				mark = "* "
+			}
+			lines = append(lines, fmt.Sprintf("%c) %s%s", v.Letters[i], mark,
+				entry.Label))
+
+		case i == 0 && len(v.shown) == 0:
+			lines = append(lines, centreString(v.Empty, listWidth))
+
+		default:
+			lines = append(lines, "")
+		}
+	}
+	lines = append(lines, "")
+
+	// Where the list is, and how to move around it.
+	status := []string{"", "", ""}
+	if v.Sortable {
+		filter := v.filter
+		if filter == "" {
+			filter = "all"
+		}
+		status = []string{
+			fmt.Sprintf("Page %d/%d  Sort: %s", v.page+1, v.Pages(),
+				listSorts[v.sortBy]),
+			"Filter: " + filter,
+			"< > page  S sort  F filter"}
+	} else if v.Pages() > 1 {
+		status[0] = fmt.Sprintf("Page %d/%d  < > page", v.page+1, v.Pages())
+	}
+	lines = append(lines, status...)
+
+	lines = append(lines, v.Footer...)
+	for i := len(v.Footer); i < listFooterLines; i++ {
+		lines = append(lines, "")
+	}
+
+	top := (ScreenHeight / 2) - (len(lines)+1)/2
+	for i, line := range lines {
+		if line != border {
+			line = fmt.Sprintf("| %-*s |", listWidth, truncateString(line,
+				listWidth))
+		}
+		Write(top+i, ScreenWidth/2, line)
+	}
+	Write(top+len(lines), ScreenWidth/2, border)
+}
+
+// centreString ... pad a string with spaces on either side so that it is
+// centred in the given width.
+/*
+ * @param     string    text
+ * @param     int       width
+ *
+ * @return    string    the centred text
+ */
+func centreString(s string, width int) string {
+
+	if len(s) >= width {
+		return s
+	}
+
+	return strings.Repeat(" ", (width-len(s))/2) + s
+}
+
+// truncateString ... cut a string down to the given length.
+/*
+ * @param     string    text
+ * @param     int       maximum length
+ *
+ * @return    string    the text, no longer than the maximum
+ */
+func truncateString(s string, length int) string {
+
+	if len(s) > length {
+		return s[:length]
+	}
+
+	return s
+}
@@ -17,6 +17,29 @@ import (
 var (
 	printVersion = false
 
+	// SeedFlag ... seed given on the command line; 0 picks one at random
+	SeedFlag int64
+
+	// RecordFile ... replay file that every key pressed is recorded to; ""
+	// records nothing
+	RecordFile = ""
+
+	// ReplayFile ... replay file to play back instead of reading the keyboard
+	ReplayFile = ""
+
+	// ReplayDelay ... milliseconds to wait between each replayed key
+	ReplayDelay = 100
+
+	// ReplayInstant ... replay without a terminal, then check the final state
+	ReplayInstant = false
+
+	// AutosaveTurns ... number of turns between each autosave; 0 disables
+	AutosaveTurns = 100
+
+	// DataDir ... directory holding additional creature, item, class and
+	// level definitions that add to or override the embedded defaults
+	DataDir = ""
+
 	// Version ... stores the version of the software
 	Version = "0.0"
 
@@ -52,11 +75,33 @@ var (
 
 	// GlobalClassTypeInfoMapIsPopulated ... check if the class types has already been populated.
 	GlobalClassTypeInfoMapIsPopulated = false
+
+	// GlobalLevelTypeInfoMap ... map of the level types, keyed by depth.
+	GlobalLevelTypeInfoMap = make(map[string]types.LevelTypeInfo)
 )
 
 func init() {
 	flag.BoolVar(&printVersion, "version", false,
 		"Print the current version of this program and exit.")
+	flag.Int64Var(&SeedFlag, "seed", 0,
+		"Seed used to generate the dungeon; the same seed always produces "+
+			"the same game. Defaults to a random seed.")
+	flag.StringVar(&RecordFile, "record", "",
+		"Record every key pressed to the given file, for use with "+
+			"--replay. Nothing is recorded by default.")
+	flag.StringVar(&ReplayFile, "replay", "",
+		"Play back the keys of a recorded session.")
+	flag.IntVar(&ReplayDelay, "replay-delay", ReplayDelay,
+		"Milliseconds to wait between each key during a replay.")
+	flag.BoolVar(&ReplayInstant, "replay-instant", false,
+		"Replay without a terminal and as fast as possible, then check "+
+			"the final state against the recording.")
+	flag.IntVar(&AutosaveTurns, "autosave", AutosaveTurns,
+		"Save the game every this many turns; 0 disables autosaving.")
+	flag.StringVar(&DataDir, "data-dir", "",
+		"Directory of creatures.json, items.json, classes.json and "+
+			"levels.json files that add to or override the built-in "+
+			"definitions.")
 }
 
 func main() {
@@ -68,13 +113,94 @@ func main() {
 		os.Exit(0)
 	}
 
+	// setup player class types, creature types, and item types; this is
+	// done before the screen is initialized so that any errors in the
+	// definition files can be printed to the console.
+	if err := LoadTypes(DataDir); err != nil {
+		fmt.Fprintln(os.Stderr, "go-roguelike: "+err.Error())
+		os.Exit(1)
+	}
+
+	// Either play back a recorded session, or record this one.
+	replay, recorder, err := prepareReplay()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "go-roguelike: "+err.Error())
+		os.Exit(1)
+	}
+
+	err = play()
+
+	// Note the final state of the game, so a replay can be checked.
+	if recorder != nil {
+		if err := recorder.Finish(G.Fingerprint()); err != nil {
+			fmt.Fprintln(os.Stderr, "go-roguelike: "+err.Error())
+		}
+	}
+
+	if replay != nil && !reportReplay(replay, err) {
+		os.Exit(1)
+	}
+
+	if err == errInputClosed {
+		fmt.Fprintln(os.Stderr, "go-roguelike: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+// play ... run the game from the menu until the player quits.
+/*
+ * @return    error    errReplayEnded if a replay ran out of keys, or
+ *                     errInputClosed if the terminal could not be read
+ */
+func play() (err error) {
+
+	// The game loop owns the game state, except while waiting for a key.
+	gameLock.Lock()
+	defer gameLock.Unlock()
+
+	// This runs after End(), so that any message is printed to a working
+	// terminal.
+	defer func() {
+
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		// A replay that runs out of keys ends the game early.
+		if r == errReplayEnded {
+			err = errReplayEnded
+			return
+		}
+
+		// So does losing the terminal, though the run is saved first so
+		// that it can be carried on.
+		if r == errInputClosed {
+			if _, saveErr := saveBeforeExit(); saveErr != nil {
+				fmt.Fprintln(os.Stderr, "go-roguelike: the game could not "+
+					"be saved: "+saveErr.Error())
+			}
+			err = errInputClosed
+			return
+		}
+
+		// Save the run before crashing, so that it can be carried on.
+		saved, saveErr := saveBeforeExit()
+		if saveErr != nil {
+			fmt.Fprintln(os.Stderr, "go-roguelike: the game crashed, and "+
+				"could not be saved: "+saveErr.Error())
+		} else if saved {
+			fmt.Fprintln(os.Stderr, "go-roguelike: the game crashed, but "+
+				"was saved first; it can be loaded from the main menu.")
+		}
+
+		panic(r)
+	}()
+
 	Init()
 	defer End()
 
-	// setup creature types, item types, and player class types
-	GlobalCreatureTypeInfoMapIsPopulated = types.GenCreatureTypes(GlobalCreatureTypeInfoMap)
-	GlobalItemTypeInfoMapIsPopulated = types.GenItemTypes(GlobalItemTypeInfoMap)
-	GlobalClassTypeInfoMapIsPopulated = types.GenClassTypes(GlobalClassTypeInfoMap)
+	handleSignals()
 
 	G.state = "menu"
 
@@ -92,5 +218,40 @@ func main() {
 		// handlers for screen output and keyboard input
 		G.Output()
 		G.Input()
+
+		G.autosave()
+	}
+
+	return nil
+}
+
+// LoadTypes ... populate the global class, creature, item and level type
+// maps
+/*
+ * @param     string    data directory, or "" for the built-in definitions
+ *
+ * @return    error     error message, if any
+ */
+func LoadTypes(dataDir string) error {
+
+	// Classes come first, since creatures may refer to them.
+	if err := types.LoadClassTypes(GlobalClassTypeInfoMap, dataDir); err != nil {
+		return err
+	}
+	GlobalClassTypeInfoMapIsPopulated = true
+
+	// Items come next, since creatures may carry them.
+	if err := types.LoadItemTypes(GlobalItemTypeInfoMap, dataDir); err != nil {
+		return err
 	}
+	GlobalItemTypeInfoMapIsPopulated = true
+
+	err := types.LoadCreatureTypes(GlobalCreatureTypeInfoMap,
+		GlobalClassTypeInfoMap, GlobalItemTypeInfoMap, dataDir)
+	if err != nil {
+		return err
+	}
+	GlobalCreatureTypeInfoMapIsPopulated = true
+
+	return types.LoadLevelTypes(GlobalLevelTypeInfoMap, dataDir)
 }
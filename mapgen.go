@@ -0,0 +1,468 @@
+/*
+ * File: mapgen.go
+ *
+ * Description: Map generators, which lay out the walls and floors of a
+ *              level along with the rooms or caverns that spawning places
+ *              creatures and items in.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/rbisewski/go_roguelike/types"
+)
+
+// MapGenerator ... lays out the tiles of a level
+type MapGenerator interface {
+
+	// Generate ... lay out a level of the given height and width.
+	/*
+	 * @param     int          height
+	 * @param     int          width
+	 * @param     rand.Rand*   map generation random number stream
+	 *
+	 * @return    Tile[]       tiles of the level
+	 *            Region[]     rooms or caverns of the level
+	 *            int          y-value of the starting point
+	 *            int          x-value of the starting point
+	 */
+	Generate(h, w int, r *rand.Rand) ([]Tile, []Region, int, int)
+}
+
+// Region ... an open part of a level, such as a room or a cavern
+type Region struct {
+
+	// Tiles of the region, as indexes into the tiles of the area.
+	Tiles []int
+}
+
+// mapGenerators ... every map generator, keyed by the name used in the
+// level definitions
+var mapGenerators = map[string]MapGenerator{
+	types.GeneratorCave:  caveGenerator{},
+	types.GeneratorRooms: roomsGenerator{},
+	types.GeneratorBSP:   bspGenerator{},
+}
+
+// Sizes used by the rooms-and-corridors and BSP generators, counting
+// floor tiles only.
+const (
+	roomMinSize   = 4
+	roomMaxHeight = 10
+	roomMaxWidth  = 16
+
+	// Tiles of level for every room the rooms-and-corridors generator
+	// tries to fit in.
+	roomDensity = 500
+
+	// Smallest section the BSP generator splits a level into, walls
+	// included.
+	bspMinLeaf = 12
+)
+
+// levelGenerator ... the map generator of the level at the given depth,
+// as set in the level definitions; levels without one are caverns.
+/*
+ * @param     int             depth of the level
+ *
+ * @return    MapGenerator    generator to lay out the level with
+ */
+func levelGenerator(depth int) MapGenerator {
+
+	info, exists := GlobalLevelTypeInfoMap[strconv.Itoa(depth)]
+	if !exists {
+		return caveGenerator{}
+	}
+
+	gen, exists := mapGenerators[info.Generator]
+	if !exists {
+		DebugLog(&G, fmt.Sprintf("levelGenerator() --> unknown "+
+			"generator %q", info.Generator))
+		return caveGenerator{}
+	}
+
+	return gen
+}
+
+// caveGenerator ... grows caverns by cellular automata, starting from
+// randomly placed walls
+type caveGenerator struct{}
+
+// Generate ... lay out a level of caverns.
+/*
+ * @param     int          height
+ * @param     int          width
+ * @param     rand.Rand*   map generation random number stream
+ *
+ * @return    Tile[]       tiles of the level
+ *            Region[]     every cavern of the level
+ *            int          y-value of the starting point
+ *            int          x-value of the starting point
+ */
+func (caveGenerator) Generate(h, w int, r *rand.Rand) ([]Tile, []Region,
+	int, int) {
+
+	// Real x,y
+	ry, rx := 0, 0
+
+	// Number of iterations to use
+	nIts := 4
+
+	// Setup an iterator
+	t := make([][]Tile, nIts)
+
+	for it := 0; it < nIts; it++ {
+
+		// Assign a tile to that given location
+		t[it] = make([]Tile, w*h)
+
+		// For all of the y-coords (height)...
+		for y := 0; y < h; y++ {
+
+			// For all of the x-coords (width)...
+			for x := 0; x < w; x++ {
+
+				// On first iteration, place random tiles.
+				if it == 0 {
+					t[it][x+y*w] = placeRandomTile(r)
+					continue
+				}
+
+				// Otherwise check for wall placement.
+				if mapBorders(y, x) || adjacentWalls(y, x, w, t[it-1]) >= 4 {
+					t[it][x+y*w] = Tile{'#', true, true}
+					continue
+				}
+
+				// Or draw ground.
+				t[it][x+y*w] = Tile{'.', false, false}
+
+				// If we are at last Iteration
+				if it == nIts-1 {
+
+					//set the spawn coords
+					ry = y
+					rx = x
+				}
+			}
+		}
+	}
+
+	return t[nIts-1], caveRegions(t[nIts-1], h, w), ry, rx
+}
+
+// caveRegions ... split the open tiles of a level into caverns, each of
+// which can be walked across without leaving it.
+/*
+ * @param     Tile[]      tiles of the level
+ * @param     int         height
+ * @param     int         width
+ *
+ * @return    Region[]    every cavern of the level
+ */
+func caveRegions(t []Tile, h, w int) []Region {
+
+	a := &Area{Tiles: t, Height: h, Width: w}
+	seen := make([]bool, len(t))
+	regions := make([]Region, 0)
+
+	for i := range t {
+
+		if seen[i] || t[i].BlockMove {
+			continue
+		}
+
+		tiles := a.reachableTiles(i/w, i%w)
+		for _, tile := range tiles {
+			seen[tile] = true
+		}
+
+		regions = append(regions, Region{tiles})
+	}
+
+	return regions
+}
+
+// roomsGenerator ... scatters rooms across solid rock at random, joining
+// each to the one before it by a corridor
+type roomsGenerator struct{}
+
+// Generate ... lay out a level of rooms and corridors.
+/*
+ * @param     int          height
+ * @param     int          width
+ * @param     rand.Rand*   map generation random number stream
+ *
+ * @return    Tile[]       tiles of the level
+ *            Region[]     every room of the level
+ *            int          y-value of the starting point
+ *            int          x-value of the starting point
+ */
+func (roomsGenerator) Generate(h, w int, r *rand.Rand) ([]Tile, []Region,
+	int, int) {
+
+	t := solidTiles(h, w)
+	rooms := make([]mapRect, 0)
+
+	// Give up on fitting in more rooms after a while, in case the level
+	// is already crowded.
+	maxRooms := Max(h*w/roomDensity, 1)
+	for attempt := 0; attempt < maxRooms*10 &&
+		len(rooms) < maxRooms; attempt++ {
+
+		rh := roomMinSize + r.Intn(roomMaxHeight-roomMinSize+1)
+		rw := roomMinSize + r.Intn(roomMaxWidth-roomMinSize+1)
+
+		// Leave room for the walls around the edge of the level.
+		if rh > h-2 || rw > w-2 {
+			continue
+		}
+
+		room := mapRect{1 + r.Intn(h-rh-1), 1 + r.Intn(w-rw-1), rh, rw}
+
+		// Rooms keep at least a wall between one another.
+		overlaps := false
+		for _, other := range rooms {
+			if room.overlaps(other) {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+
+		carveRoom(t, w, room)
+		if len(rooms) > 0 {
+			carveCorridor(t, w, rooms[len(rooms)-1], room, r)
+		}
+		rooms = append(rooms, room)
+	}
+
+	// Levels too small for even a single room are left to the caverns.
+	if len(rooms) == 0 {
+		return caveGenerator{}.Generate(h, w, r)
+	}
+
+	y, x := rooms[0].center()
+
+	return t, roomRegions(rooms, w), y, x
+}
+
+// bspGenerator ... splits the level in two again and again, places a room
+// in every section, and joins the halves of each split by a corridor
+type bspGenerator struct{}
+
+// Generate ... lay out a level of rooms by binary space partitioning.
+/*
+ * @param     int          height
+ * @param     int          width
+ * @param     rand.Rand*   map generation random number stream
+ *
+ * @return    Tile[]       tiles of the level
+ *            Region[]     every room of the level
+ *            int          y-value of the starting point
+ *            int          x-value of the starting point
+ */
+func (g bspGenerator) Generate(h, w int, r *rand.Rand) ([]Tile, []Region,
+	int, int) {
+
+	// Levels too small to hold a section are left to the caverns.
+	if h-2 < bspMinLeaf || w-2 < bspMinLeaf {
+		return caveGenerator{}.Generate(h, w, r)
+	}
+
+	t := solidTiles(h, w)
+	rooms := make([]mapRect, 0)
+
+	// The outermost tiles of the level are always wall.
+	g.partition(t, w, mapRect{1, 1, h - 2, w - 2}, r, &rooms)
+
+	y, x := rooms[0].center()
+
+	return t, roomRegions(rooms, w), y, x
+}
+
+// partition ... split a section of the level in two, or place a room in
+// it once it is too small to split any further.
+/*
+ * @param     Tile[]       tiles of the level
+ * @param     int          width of the level
+ * @param     mapRect      section of the level
+ * @param     rand.Rand*   map generation random number stream
+ * @param     mapRect[]*   rooms placed so far
+ *
+ * @return    mapRect      a room within the section, to join others to
+ */
+func (g bspGenerator) partition(t []Tile, w int, section mapRect,
+	r *rand.Rand, rooms *[]mapRect) mapRect {
+
+	canSplitY := section.h >= 2*bspMinLeaf
+	canSplitX := section.w >= 2*bspMinLeaf
+
+	// Sections too small to split get a room of their own, leaving a wall
+	// between it and the neighbouring sections.
+	if !canSplitY && !canSplitX {
+
+		rh := roomMinSize + r.Intn(Min(section.h-2, roomMaxHeight)-
+			roomMinSize+1)
+		rw := roomMinSize + r.Intn(Min(section.w-2, roomMaxWidth)-
+			roomMinSize+1)
+		room := mapRect{section.y + 1 + r.Intn(section.h-rh-1),
+			section.x + 1 + r.Intn(section.w-rw-1), rh, rw}
+
+		carveRoom(t, w, room)
+		*rooms = append(*rooms, room)
+		return room
+	}
+
+	// Split across the longer side, so that sections stay roughly square.
+	splitY := canSplitY
+	if canSplitY && canSplitX {
+		splitY = section.h > section.w || (section.h == section.w &&
+			TossCoin(r))
+	}
+
+	var first, second mapRect
+	if splitY {
+		at := bspMinLeaf + r.Intn(section.h-2*bspMinLeaf+1)
+		first = mapRect{section.y, section.x, at, section.w}
+		second = mapRect{section.y + at, section.x, section.h - at, section.w}
+	} else {
+		at := bspMinLeaf + r.Intn(section.w-2*bspMinLeaf+1)
+		first = mapRect{section.y, section.x, section.h, at}
+		second = mapRect{section.y, section.x + at, section.h, section.w - at}
+	}
+
+	// Join the two halves, so that every room can be walked to.
+	a := g.partition(t, w, first, r, rooms)
+	b := g.partition(t, w, second, r, rooms)
+	carveCorridor(t, w, a, b, r)
+
+	if TossCoin(r) {
+		return a
+	}
+
+	return b
+}
+
+// mapRect ... a rectangle of tiles, such as a room
+type mapRect struct {
+	y int
+	x int
+	h int
+	w int
+}
+
+// center ... the tile at the middle of the rectangle.
+/*
+ * @return    int    y-value
+ *            int    x-value
+ */
+func (m mapRect) center() (int, int) {
+	return m.y + m.h/2, m.x + m.w/2
+}
+
+// overlaps ... whether the rectangle overlaps another, or touches it with
+// no wall left in between.
+/*
+ * @param     mapRect    the other rectangle
+ *
+ * @return    bool       true if they overlap
+ */
+func (m mapRect) overlaps(o mapRect) bool {
+	return m.y <= o.y+o.h && o.y <= m.y+m.h &&
+		m.x <= o.x+o.w && o.x <= m.x+m.w
+}
+
+// solidTiles ... a level of nothing but wall.
+/*
+ * @param     int       height
+ * @param     int       width
+ *
+ * @return    Tile[]    tiles of the level
+ */
+func solidTiles(h, w int) []Tile {
+
+	t := make([]Tile, h*w)
+	for i := range t {
+		t[i] = Tile{'#', true, true}
+	}
+
+	return t
+}
+
+// carveRoom ... turn a rectangle of the level into floor.
+/*
+ * @param     Tile[]     tiles of the level
+ * @param     int        width of the level
+ * @param     mapRect    the room
+ *
+ * @return    none
+ */
+func carveRoom(t []Tile, w int, room mapRect) {
+
+	for y := room.y; y < room.y+room.h; y++ {
+		for x := room.x; x < room.x+room.w; x++ {
+			t[x+y*w] = Tile{'.', false, false}
+		}
+	}
+}
+
+// carveCorridor ... dig an L-shaped corridor between the middles of two
+// rooms, bending at one corner or the other.
+/*
+ * @param     Tile[]       tiles of the level
+ * @param     int          width of the level
+ * @param     mapRect      the room the corridor starts in
+ * @param     mapRect      the room the corridor ends in
+ * @param     rand.Rand*   map generation random number stream
+ *
+ * @return    none
+ */
+func carveCorridor(t []Tile, w int, from, to mapRect, r *rand.Rand) {
+
+	y1, x1 := from.center()
+	y2, x2 := to.center()
+
+	across := mapRect{y1, Min(x1, x2), 1, Max(x1, x2) - Min(x1, x2) + 1}
+	down := mapRect{Min(y1, y2), x2, Max(y1, y2) - Min(y1, y2) + 1, 1}
+
+	// The bend of the corridor is either level with the first room, or
+	// in line with the second.
+	if TossCoin(r) {
+		across.y, down.x = y2, x1
+	}
+
+	carveRoom(t, w, across)
+	carveRoom(t, w, down)
+}
+
+// roomRegions ... the floor tiles of each room, as regions.
+/*
+ * @param     mapRect[]    rooms of the level
+ * @param     int          width of the level
+ *
+ * @return    Region[]     a region for every room
+ */
+func roomRegions(rooms []mapRect, w int) []Region {
+
+	regions := make([]Region, 0, len(rooms))
+	for _, room := range rooms {
+
+		tiles := make([]int, 0, room.h*room.w)
+		for y := room.y; y < room.y+room.h; y++ {
+			for x := room.x; x < room.x+room.w; x++ {
+				tiles = append(tiles, x+y*w)
+			}
+		}
+
+		regions = append(regions, Region{tiles})
+	}
+
+	return regions
+}
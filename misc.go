@@ -15,23 +15,26 @@ import (
 
 // TossCoin ... Randomly returns "true" or "false"
 /*
- * @returns    bool    whether the coin was heads (true) or tails (false)
+ * @param      rand.Rand*   random number stream to draw from
+ *
+ * @returns    bool         whether the coin was heads (true) or tails (false)
  */
-func TossCoin() bool {
-	return rand.Intn(100) > 50
+func TossCoin(r *rand.Rand) bool {
+	return r.Intn(100) > 50
 }
 
 // getRandomNumBetweenZeroAndMax ... Returns a random number between 0 and X
 /*
- * @param      int     highest possible random number
+ * @param      rand.Rand*   random number stream to draw from
+ * @param      int          highest possible random number
  *
- * @returns    int     random number between 0 and maximum
+ * @returns    int          random number between 0 and maximum
  */
-func getRandomNumBetweenZeroAndMax(maximum int) int {
+func getRandomNumBetweenZeroAndMax(r *rand.Rand, maximum int) int {
 	if maximum < 1 {
 		return 0
 	}
-	return rand.Intn(maximum)
+	return r.Intn(maximum)
 }
 
 // Min ... Get the minimum of a list of int values (i.e. the lowest value)
@@ -0,0 +1,206 @@
+/*
+ * File: pathfind/astar.go
+ *
+ * Description: A* pathfinding over a grid of tiles, allowing moves in
+ *              all eight directions.
+ */
+
+package pathfind
+
+import "container/heap"
+
+// Grid ... the map being searched
+type Grid interface {
+
+	// Height and width of the grid.
+	Size() (int, int)
+
+	// Whether or not the tile at (x,y) can be walked thru.
+	Passable(y, x int) bool
+}
+
+// Point ... an (x,y) coord on the grid
+type Point struct {
+	Y int
+	X int
+}
+
+// Cost of a straight and a diagonal step. In the game a diagonal step is
+// a single move, just like a straight one, but it costs a little more
+// here so that straight paths win when the two are otherwise equal.
+const (
+	straightCost = 10
+	diagonalCost = 11
+)
+
+// node ... an entry of the open list
+type node struct {
+	index    int
+	priority int
+}
+
+// openList ... priority queue of nodes, lowest estimated cost first
+type openList []node
+
+func (o openList) Len() int            { return len(o) }
+func (o openList) Less(i, j int) bool  { return o[i].priority < o[j].priority }
+func (o openList) Swap(i, j int)       { o[i], o[j] = o[j], o[i] }
+func (o *openList) Push(v interface{}) { *o = append(*o, v.(node)) }
+func (o *openList) Pop() interface{} {
+	old := *o
+	n := old[len(old)-1]
+	*o = old[:len(old)-1]
+	return n
+}
+
+// Find ... search for the shortest path between two points.
+//
+// The goal is always treated as passable, so that a path can lead up to
+// an occupied tile such as the one the player stands on. To keep the cost
+// bounded on large maps, the search gives up after expanding maxNodes
+// tiles; a maxNodes of zero or less means no limit.
+/*
+ * @param     Grid       grid to search
+ * @param     Point      starting point
+ * @param     Point      goal
+ * @param     int        maximum number of tiles to expand
+ *
+ * @return    Point[]    steps from start to goal, excluding the start, or
+ *                       nil if no path was found
+ */
+func Find(g Grid, start Point, goal Point, maxNodes int) []Point {
+
+	if g == nil {
+		return nil
+	}
+
+	h, w := g.Size()
+	if !inBounds(start, h, w) || !inBounds(goal, h, w) || start == goal {
+		return nil
+	}
+
+	startIndex := start.X + start.Y*w
+	goalIndex := goal.X + goal.Y*w
+
+	// Cheapest known cost to reach each tile, and the tile it came from.
+	cost := map[int]int{startIndex: 0}
+	cameFrom := make(map[int]int)
+	closed := make(map[int]bool)
+
+	open := &openList{{startIndex, heuristic(start, goal)}}
+	expanded := 0
+
+	for open.Len() > 0 {
+
+		current := heap.Pop(open).(node)
+		if closed[current.index] {
+			continue
+		}
+
+		if current.index == goalIndex {
+			return buildPath(cameFrom, startIndex, goalIndex, w)
+		}
+
+		closed[current.index] = true
+
+		expanded++
+		if maxNodes > 0 && expanded > maxNodes {
+			return nil
+		}
+
+		cy, cx := current.index/w, current.index%w
+
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+
+				if dy == 0 && dx == 0 {
+					continue
+				}
+
+				next := Point{cy + dy, cx + dx}
+				if !inBounds(next, h, w) {
+					continue
+				}
+
+				nextIndex := next.X + next.Y*w
+				if closed[nextIndex] {
+					continue
+				}
+				if nextIndex != goalIndex && !g.Passable(next.Y, next.X) {
+					continue
+				}
+
+				step := straightCost
+				if dy != 0 && dx != 0 {
+					step = diagonalCost
+				}
+
+				newCost := cost[current.index] + step
+				if known, seen := cost[nextIndex]; seen && known <= newCost {
+					continue
+				}
+
+				cost[nextIndex] = newCost
+				cameFrom[nextIndex] = current.index
+				heap.Push(open, node{nextIndex, newCost + heuristic(next, goal)})
+			}
+		}
+	}
+
+	return nil
+}
+
+// heuristic ... octile distance between two points, which never
+// overestimates the cost when diagonal moves are allowed.
+/*
+ * @param     Point    from
+ * @param     Point    to
+ *
+ * @return    int      estimated cost
+ */
+func heuristic(a, b Point) int {
+
+	dy, dx := abs(a.Y-b.Y), abs(a.X-b.X)
+	if dy > dx {
+		dy, dx = dx, dy
+	}
+
+	return dy*diagonalCost + (dx-dy)*straightCost
+}
+
+// buildPath ... walk back from the goal to the start.
+/*
+ * @param     map        tile each tile was reached from
+ * @param     int        index of the start
+ * @param     int        index of the goal
+ * @param     int        grid width
+ *
+ * @return    Point[]    steps from start to goal, excluding the start
+ */
+func buildPath(cameFrom map[int]int, startIndex, goalIndex, w int) []Point {
+
+	path := make([]Point, 0)
+	for i := goalIndex; i != startIndex; i = cameFrom[i] {
+		path = append(path, Point{i / w, i % w})
+	}
+
+	// Reverse, so the first step comes first.
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+// inBounds ... whether a point lies on a grid of the given size.
+func inBounds(p Point, h, w int) bool {
+	return p.Y >= 0 && p.Y < h && p.X >= 0 && p.X < w
+}
+
+// abs ... absolute value of an int.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,219 @@
+/*
+ * File: pathfind/astar_test.go
+ *
+ * Description: Checks the paths found around walls, to occupied goals and
+ *              under a search limit, and times a search over a map the
+ *              size of a dungeon level.
+ */
+
+package pathfind
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// textGrid ... grid drawn as text, where # is a wall and @ a creature
+// standing in the way; S and G mark the start and goal
+type textGrid []string
+
+func (t textGrid) Size() (int, int) {
+	return len(t), len(t[0])
+}
+
+func (t textGrid) Passable(y, x int) bool {
+	return t[y][x] != '#' && t[y][x] != '@'
+}
+
+// find ... the point marked with the given rune.
+func (t textGrid) find(ch byte) Point {
+
+	for y, row := range t {
+		for x := range row {
+			if row[x] == ch {
+				return Point{y, x}
+			}
+		}
+	}
+
+	return Point{-1, -1}
+}
+
+func TestFind(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		grid     textGrid
+		maxNodes int
+
+		// Number of steps in the path, or 0 if there should be none.
+		want int
+	}{
+		{"open ground", textGrid{
+			"S....",
+			".....",
+			"....G",
+		}, 0, 4},
+		{"around a wall", textGrid{
+			"......",
+			"..#...",
+			"S.#.G.",
+			"..#...",
+			"......",
+		}, 0, 4},
+		{"no path", textGrid{
+			"..#...",
+			"S.#.G.",
+			"..#...",
+		}, 0, 0},
+		{"goal occupied", textGrid{
+			"......",
+			"S.#.@.",
+			"......",
+		}, 0, 4},
+		{"walled in but for the goal", textGrid{
+			"###",
+			"#S@",
+			"###",
+		}, 0, 1},
+		{"within the limit", textGrid{
+			"##########",
+			"S........G",
+			"##########",
+		}, 20, 9},
+		{"past the limit", textGrid{
+			"##########",
+			"S........G",
+			"##########",
+		}, 5, 0},
+	}
+
+	for _, tt := range tests {
+
+		start, goal := tt.grid.find('S'), tt.grid.find('G')
+		if goal.Y < 0 {
+			goal = tt.grid.find('@')
+		}
+
+		path := Find(tt.grid, start, goal, tt.maxNodes)
+		if len(path) != tt.want {
+			t.Errorf("%s: Find() = %v, want %d steps", tt.name, path, tt.want)
+			continue
+		}
+		if tt.want == 0 {
+			continue
+		}
+
+		// Every step is to a neighbouring tile that can be walked thru,
+		// apart from the goal itself.
+		prev := start
+		for i, p := range path {
+			if abs(p.Y-prev.Y) > 1 || abs(p.X-prev.X) > 1 ||
+				(i < len(path)-1 && !tt.grid.Passable(p.Y, p.X)) {
+				t.Errorf("%s: bad step %v -> %v in %v", tt.name, prev, p,
+					path)
+			}
+			prev = p
+		}
+		if prev != goal {
+			t.Errorf("%s: path %v ends at %v, want %v", tt.name, path, prev,
+				goal)
+		}
+	}
+}
+
+func TestFindBadInput(t *testing.T) {
+
+	grid := textGrid{"...", "...", "..."}
+
+	if path := Find(nil, Point{0, 0}, Point{2, 2}, 0); path != nil {
+		t.Errorf("Find() on no grid = %v, want nil", path)
+	}
+	if path := Find(grid, Point{1, 1}, Point{1, 1}, 0); path != nil {
+		t.Errorf("Find() to the start = %v, want nil", path)
+	}
+	if path := Find(grid, Point{0, 0}, Point{3, 0}, 0); path != nil {
+		t.Errorf("Find() off the grid = %v, want nil", path)
+	}
+}
+
+// caveGrid ... a cave the size of a dungeon level, grown from random
+// walls by cellular automata, much like the cave levels of the game
+type caveGrid struct {
+	h, w  int
+	walls []bool
+}
+
+func (c *caveGrid) Size() (int, int) {
+	return c.h, c.w
+}
+
+func (c *caveGrid) Passable(y, x int) bool {
+	return !c.walls[x+y*c.w]
+}
+
+// newCaveGrid ... grow a cave of the given size from the given seed.
+func newCaveGrid(h, w int, seed int64) *caveGrid {
+
+	r := rand.New(rand.NewSource(seed))
+	c := &caveGrid{h, w, make([]bool, h*w)}
+	for i := range c.walls {
+		c.walls[i] = r.Intn(100) < 45
+	}
+
+	for pass := 0; pass < 4; pass++ {
+		next := make([]bool, len(c.walls))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				walls := 0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						p := Point{y + dy, x + dx}
+						if !inBounds(p, h, w) || c.walls[p.X+p.Y*w] {
+							walls++
+						}
+					}
+				}
+				next[x+y*w] = walls >= 5
+			}
+		}
+		c.walls = next
+	}
+
+	return c
+}
+
+// BenchmarkFind ... one search on a 240x250 cave, between open tiles a
+// chase apart, both capped at the 400 tiles that chaseSearchLimit lets a
+// monster search and with no cap at all. A level holds a few hundred
+// creatures, so a capped search has to come in well under a millisecond
+// to keep a turn quick.
+func BenchmarkFind(b *testing.B) {
+
+	cave := newCaveGrid(240, 250, 1)
+	r := rand.New(rand.NewSource(2))
+
+	// Pairs of open tiles up to 20 tiles apart, twice as far as a pack
+	// hunts the player from, so that some searches run into the cap.
+	pairs := make([][2]Point, 0, 256)
+	for len(pairs) < cap(pairs) {
+		start := Point{r.Intn(240), r.Intn(250)}
+		goal := Point{start.Y + r.Intn(41) - 20, start.X + r.Intn(41) - 20}
+		if inBounds(goal, 240, 250) && cave.Passable(start.Y, start.X) &&
+			cave.Passable(goal.Y, goal.X) {
+			pairs = append(pairs, [2]Point{start, goal})
+		}
+	}
+
+	for _, limit := range []struct {
+		name     string
+		maxNodes int
+	}{{"chase limit", 400}, {"no limit", 0}} {
+		b.Run(limit.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				pair := pairs[i%len(pairs)]
+				Find(cave, pair[0], pair[1], limit.maxNodes)
+			}
+		})
+	}
+}
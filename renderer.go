@@ -0,0 +1,69 @@
+/*
+ * File: renderer.go
+ *
+ * Description: Interfaces that sit between the engine and the terminal,
+ *              so that the game can be drawn and driven by either ncurses
+ *              or an in-memory frame buffer.
+ */
+
+package main
+
+// Renderer ... output backend used by the engine draw functions.
+//
+// The screen is split into the same sections that the engine has always
+// used: the main console (stdscr), the game pad holding the whole area
+// map, the stats window on the right, the message log beneath the game
+// pad, and a single line of debug output at the bottom.
+type Renderer interface {
+
+	// Prepare the output device and return the console height and width.
+	Start() (int, int)
+
+	// Carve out the stats, debug and message log sections of the console.
+	Layout(screenHeight, screenWidth, consoleHeight, consoleWidth int)
+
+	// Restore the output device back to its original state.
+	End()
+
+	// Wipe the main console.
+	Clear()
+
+	// Allocate a new game pad of the given height and width.
+	NewPad(h, w int) bool
+
+	// Draw a rune onto the game pad; a colour of 0 means no colour pair.
+	DrawPad(y, x int, ch rune, colour int)
+
+	// Copy the section of the game pad starting at (fromY, fromX) onto
+	// the top-left corner of the console.
+	RefreshPad(fromY, fromX, height, width int)
+
+	// Write a string onto the main console.
+	Write(y, x int, s string)
+
+	// Write a string onto the stats window.
+	WriteStats(y, x int, s string)
+
+	// Queue the stats window for the next update.
+	RefreshStats()
+
+	// Write a string onto the given line of the message log.
+	WriteLog(line int, s string)
+
+	// Copy the message log, starting at fromLine, onto the console rows
+	// between top and bottom.
+	RefreshLog(fromLine, top, bottom, width int)
+
+	// Replace the contents of the debug line.
+	WriteDebug(s string)
+
+	// Push all of the queued changes to the output device.
+	Update()
+}
+
+// InputSource ... keyboard backend used by GetInput.
+type InputSource interface {
+
+	// Block until a key is available and return it as a string.
+	GetKey() string
+}
@@ -0,0 +1,344 @@
+/*
+ * File: replay.go
+ *
+ * Description: Records every key pressed during a session to a replay
+ *              file, and plays those keys back thru Game.Input so that a
+ *              session can be reproduced exactly.
+ */
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"time"
+)
+
+// ReplayFormat ... version of the replay file layout
+const ReplayFormat = 1
+
+// errReplayEnded ... raised when a replay runs out of keys before the
+// game was quit, e.g. because the recorded session crashed.
+var errReplayEnded = errors.New("replay ended before the game was quit")
+
+// replayHeader ... first line of a replay file
+type replayHeader struct {
+	Format  int    `json:"format"`
+	Version string `json:"version"`
+	Seed    int64  `json:"seed"`
+}
+
+// replayEntry ... every following line of a replay file; either a single
+// key, or the fingerprint of the game state when the session ended.
+type replayEntry struct {
+	Key   *string `json:"key,omitempty"`
+	Final string  `json:"final,omitempty"`
+}
+
+// KeyRecorder ... InputSource that writes every key read from another
+// InputSource to a replay file.
+type KeyRecorder struct {
+
+	// Where the keys are actually read from.
+	source InputSource
+
+	// Replay file, written one line per key so that nothing is lost if
+	// the game crashes.
+	file    *os.File
+	encoder *json.Encoder
+
+	// Number of writes in a row that failed; once there are too many,
+	// recording stops.
+	failures int
+}
+
+// maxRecordFailures ... failed writes in a row before recording stops
+const maxRecordFailures = 10
+
+// ReplayInput ... InputSource that hands out the keys of a replay file.
+type ReplayInput struct {
+
+	// Details from the first line of the replay file.
+	Version string
+	Seed    int64
+
+	// Fingerprint of the game state at the end of the recorded session,
+	// or "" if the session never ended cleanly.
+	Final string
+
+	// Keys of the recorded session, and the next one to hand out.
+	keys []string
+	next int
+
+	// Pause before handing out each key, so the replay can be watched.
+	delay time.Duration
+}
+
+// NewKeyRecorder ... start recording keys to a new replay file
+/*
+ * @param     string          path of the replay file
+ * @param     int64           seed of the session
+ * @param     InputSource     where keys are read from
+ *
+ * @return    KeyRecorder*    pointer to the new recorder
+ *            error           error message, if any
+ */
+func NewKeyRecorder(path string, seed int64,
+	source InputSource) (*KeyRecorder, error) {
+
+	if path == "" || source == nil {
+		return nil, fmt.Errorf("NewKeyRecorder() --> invalid input")
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &KeyRecorder{source, file, json.NewEncoder(file), 0}
+
+	err = rec.encoder.Encode(replayHeader{ReplayFormat, Version, seed})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+// GetKey ... read a key from the source and append it to the replay.
+func (rec *KeyRecorder) GetKey() string {
+
+	key := rec.source.GetKey()
+
+	// Failed reads are not keys, so there is nothing to replay; and once
+	// the replay file cannot be written to, stop trying.
+	if key == "" || rec.failures >= maxRecordFailures {
+		return key
+	}
+
+	// A failed write should never interrupt the game itself.
+	if err := rec.encoder.Encode(replayEntry{Key: &key}); err != nil {
+		DebugLog(&G, "KeyRecorder.GetKey() --> "+err.Error())
+		rec.failures++
+		return key
+	}
+	rec.failures = 0
+
+	return key
+}
+
+// Finish ... write the final game state and close the replay file.
+/*
+ * @param     string    fingerprint of the game state
+ *
+ * @return    error     error message, if any
+ */
+func (rec *KeyRecorder) Finish(final string) error {
+
+	if err := rec.encoder.Encode(replayEntry{Final: final}); err != nil {
+		rec.file.Close()
+		return err
+	}
+
+	return rec.file.Close()
+}
+
+// LoadReplay ... read the keys of a replay file
+/*
+ * @param     string          path of the replay file
+ * @param     time.Duration   pause before handing out each key
+ *
+ * @return    ReplayInput*    pointer to the loaded replay
+ *            error           error message, if any
+ */
+func LoadReplay(path string, delay time.Duration) (*ReplayInput, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	replay := &ReplayInput{keys: make([]string, 0), delay: delay}
+
+	// The first line holds the seed and version.
+	var header replayHeader
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("%s: empty replay file", path)
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, fmt.Errorf("%s:1: bad header: %v", path, err)
+	}
+	if header.Format != ReplayFormat {
+		return nil, fmt.Errorf("%s: unsupported replay format %d",
+			path, header.Format)
+	}
+	replay.Version, replay.Seed = header.Version, header.Seed
+
+	// Every line after that is a key, or the final state.
+	for line := 2; scanner.Scan(); line++ {
+
+		var entry replayEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+
+			// The session may have been cut off mid-write by a crash,
+			// so stop at the first bad line rather than failing.
+			DebugLog(&G, fmt.Sprintf("LoadReplay() --> %s:%d: %v",
+				path, line, err))
+			break
+		}
+
+		if entry.Key != nil {
+			replay.keys = append(replay.keys, *entry.Key)
+		} else if entry.Final != "" {
+			replay.Final = entry.Final
+		}
+	}
+
+	return replay, scanner.Err()
+}
+
+// GetKey ... hand out the next recorded key.
+func (r *ReplayInput) GetKey() string {
+
+	if r.next >= len(r.keys) {
+		panic(errReplayEnded)
+	}
+
+	if r.delay > 0 {
+		time.Sleep(r.delay)
+	}
+
+	r.next++
+
+	return r.keys[r.next-1]
+}
+
+// Fingerprint ... summarize the state of the game, so that a replayed
+// session can be checked against the recorded one.
+/*
+ * @return    string    fingerprint of the game state
+ */
+func (g *Game) Fingerprint() string {
+
+	if g == nil || g.Player == nil || g.Dungeon == nil {
+		return "no game"
+	}
+
+	// Hash the position and health of every creature on every level.
+	h := fnv.New64a()
+	for depth := 1; depth <= MaxDepth; depth++ {
+
+		a, exists := g.Dungeon.Levels[depth]
+		if !exists {
+			continue
+		}
+
+		for _, m := range a.Creatures {
+			fmt.Fprintf(h, "%d:%s:%d:%d:%d;", depth, m.name, m.Y, m.X, m.Hp)
+		}
+		for _, itm := range a.Items {
+			fmt.Fprintf(h, "%d:%s:%d:%d;", depth, itm.name, itm.Y, itm.X)
+		}
+	}
+
+	return fmt.Sprintf("seed=%d depth=%d pos=%d,%d hp=%d/%d world=%x",
+		g.Seed, g.Depth, g.Player.Y, g.Player.X, g.Player.Hp,
+		g.Player.MaxHp, h.Sum64())
+}
+
+// prepareReplay ... swap in the replay or recording input sources, as
+// requested on the command line.
+/*
+ * @return    ReplayInput*    replay being played back, if any
+ *            KeyRecorder*    recorder of this session, if any
+ *            error           error message, if any
+ */
+func prepareReplay() (*ReplayInput, *KeyRecorder, error) {
+
+	if ReplayFile != "" {
+
+		delay := time.Duration(ReplayDelay) * time.Millisecond
+		if ReplayInstant {
+			delay = 0
+		}
+
+		replay, err := LoadReplay(ReplayFile, delay)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if replay.Version != Version {
+			fmt.Fprintf(os.Stderr, "go-roguelike: replay was recorded with "+
+				"v%s, but this is v%s\n", replay.Version, Version)
+		}
+
+		// Generate the exact same dungeon as the recorded session.
+		SeedFlag = replay.Seed
+
+		// An instant replay has no need for a terminal.
+		if ReplayInstant {
+			Display = NewFrameBuffer(50, 160)
+		}
+
+		Keyboard = replay
+
+		return replay, nil, nil
+	}
+
+	if RecordFile == "" {
+		return nil, nil, nil
+	}
+
+	// The seed must be known before the first key is recorded, so pick it
+	// now rather than when the game begins.
+	SeedFlag = NewSeed()
+
+	recorder, err := NewKeyRecorder(RecordFile, SeedFlag, Keyboard)
+	if err != nil {
+		return nil, nil, err
+	}
+	Keyboard = recorder
+
+	return nil, recorder, nil
+}
+
+// reportReplay ... tell the end-user how the replay ended.
+/*
+ * @param     ReplayInput*    replay that was played back
+ * @param     error           error returned by play(), if any
+ *
+ * @return    bool            whether the replay matched the recording
+ */
+func reportReplay(replay *ReplayInput, err error) bool {
+
+	if err != nil {
+		fmt.Printf("Replay stopped after %d keys: %v\n", replay.next, err)
+		return replay.Final == ""
+	}
+
+	final := G.Fingerprint()
+
+	if replay.Final == "" {
+		fmt.Printf("Replay finished after %d keys; the recording has no "+
+			"final state to compare.\n  got: %s\n", replay.next, final)
+		return true
+	}
+
+	if final != replay.Final {
+		fmt.Printf("Replay diverged from the recording.\n  expected: %s\n"+
+			"  got:      %s\n", replay.Final, final)
+		return false
+	}
+
+	fmt.Printf("Replay finished after %d keys; final state matches.\n"+
+		"  %s\n", replay.next, final)
+
+	return true
+}
@@ -0,0 +1,191 @@
+/*
+ * File: rng.go
+ *
+ * Description: Seeded random number streams, so that the same seed
+ *              always produces the same dungeon and monster placement.
+ */
+
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Identifiers of the random number streams, used when deriving seeds.
+const (
+	mapStream = iota + 1
+	spawnStream
+	aiStream
+	combatStream
+)
+
+// RandomStreams ... independent random number generators, one for each
+// subsystem, so that e.g. extra AI rolls never change the map layout.
+type RandomStreams struct {
+
+	// Used to generate the level layout.
+	Map *rand.Rand
+
+	// Used to place the staircases and creatures of a level.
+	Spawn *rand.Rand
+
+	// Used by the monster AI.
+	AI *rand.Rand
+
+	// Used to resolve attacks.
+	Combat *rand.Rand
+
+	// Sources of the AI and combat streams, which run on for the whole
+	// game and so have to be saved along with it.
+	aiSource     *countingSource
+	combatSource *countingSource
+}
+
+// countingSource ... random number source that counts the numbers it has
+// handed out, so that a stream can be wound forward to the same spot
+// after a load
+type countingSource struct {
+	src   rand.Source64
+	draws uint64
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.draws = 0
+	s.src.Seed(seed)
+}
+
+// NewRandomStreams ... create every stream for a given seed; the map and
+// spawn streams are reset again for each level via ForLevel().
+/*
+ * @param     int64             game seed
+ *
+ * @return    RandomStreams*    pointer to the new set of streams
+ */
+func NewRandomStreams(seed int64) *RandomStreams {
+
+	ai := newCountingSource(seed, aiStream)
+	combat := newCountingSource(seed, combatStream)
+
+	return &RandomStreams{
+		Map:          newStream(seed, mapStream, 0),
+		Spawn:        newStream(seed, spawnStream, 0),
+		AI:           rand.New(ai),
+		Combat:       rand.New(combat),
+		aiSource:     ai,
+		combatSource: combat,
+	}
+}
+
+// Draws ... how many numbers the AI and combat streams have handed out
+// since the start of the game.
+/*
+ * @return    uint64    draws from the AI stream
+ *            uint64    draws from the combat stream
+ */
+func (r *RandomStreams) Draws() (uint64, uint64) {
+	return r.aiSource.draws, r.combatSource.draws
+}
+
+// Resume ... wind the AI and combat streams of a fresh set forward by the
+// given number of draws, so that a loaded game rolls exactly as it would
+// have had it never been saved.
+/*
+ * @param     uint64    draws from the AI stream
+ * @param     uint64    draws from the combat stream
+ *
+ * @return    none
+ */
+func (r *RandomStreams) Resume(aiDraws, combatDraws uint64) {
+
+	for r.aiSource.draws < aiDraws {
+		r.aiSource.Int63()
+	}
+
+	for r.combatSource.draws < combatDraws {
+		r.combatSource.Int63()
+	}
+}
+
+// ForLevel ... reset the map and spawn streams for the given depth, so
+// that a level always comes out the same no matter when it is generated.
+/*
+ * @param     int64    game seed
+ * @param     int      depth of the level
+ *
+ * @return    none
+ */
+func (r *RandomStreams) ForLevel(seed int64, depth int) {
+	r.Map = newStream(seed, mapStream, depth)
+	r.Spawn = newStream(seed, spawnStream, depth)
+}
+
+// newStream ... create a generator from a seed derived from the game seed.
+/*
+ * @param     int64         game seed
+ * @param     int           stream identifier
+ * @param     int           depth of the level, or 0 if not level specific
+ *
+ * @return    rand.Rand*    pointer to the new generator
+ */
+func newStream(seed int64, stream int, depth int) *rand.Rand {
+	return rand.New(rand.NewSource(deriveSeed(seed, stream, depth)))
+}
+
+// newCountingSource ... create a counting source from a seed derived from
+// the game seed, for a stream that is not level specific.
+/*
+ * @param     int64              game seed
+ * @param     int                stream identifier
+ *
+ * @return    countingSource*    pointer to the new source
+ */
+func newCountingSource(seed int64, stream int) *countingSource {
+	src := rand.NewSource(deriveSeed(seed, stream, 0)).(rand.Source64)
+	return &countingSource{src, 0}
+}
+
+// deriveSeed ... mix the game seed with a stream and depth, via the
+// splitmix64 finalizer, so that neighbouring seeds give unrelated streams.
+/*
+ * @param     int64    game seed
+ * @param     int      stream identifier
+ * @param     int      depth of the level
+ *
+ * @return    int64    derived seed
+ */
+func deriveSeed(seed int64, stream int, depth int) int64 {
+
+	z := uint64(seed) + uint64(stream)*0x9e3779b97f4a7c15 +
+		uint64(depth)*0xbf58476d1ce4e5b9
+
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z = z ^ (z >> 31)
+
+	return int64(z)
+}
+
+// NewSeed ... pick a seed for a new game; the --seed flag wins if given,
+// otherwise one is generated from the nanosecond time.
+/*
+ * @return    int64    game seed
+ */
+func NewSeed() int64 {
+
+	if SeedFlag != 0 {
+		return SeedFlag
+	}
+
+	return time.Now().UnixNano()
+}
@@ -0,0 +1,644 @@
+/*
+ * File: save.go
+ *
+ * Description: Handles the save file format, which lists the state of
+ *              the player, every level, creature and item explicitly so
+ *              that nothing is lost between saving and loading.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rbisewski/go_roguelike/types"
+)
+
+// SaveVersion ... version of the save file schema written by this build
+//
+// Version 1 was the original format, a bare gob encoding of the Game
+// struct that lost every unexported field. Version 2 is the explicit
+// schema below.
+//
+// When the schema changes, bump SaveVersion, keep the old body struct
+// around under a versioned name, and add a case to decodeSaveBody that
+// converts it forward.
+const SaveVersion = 2
+
+// saveMagic ... marks a file as a save file of this game
+const saveMagic = "go-roguelike save"
+
+// saveEnvelope ... outer layer of a save file, which allows the body to
+// be checked before it is decoded
+type saveEnvelope struct {
+	Magic    string
+	Version  int
+	Summary  saveSummary
+	Checksum uint32
+	Body     []byte
+}
+
+// saveSummary ... details of a save shown on the load screen, so that the
+// whole body need not be decoded to list them
+type saveSummary struct {
+	Name    string
+	Class   string
+	Depth   int
+	Turns   int
+	SavedAt time.Time
+}
+
+// saveGame ... body of a save file
+type saveGame struct {
+	Seed  int64
+	Depth int
+	Turns int
+	Ticks int
+
+	// Numbers drawn so far from the AI and combat streams.
+	AIDraws     uint64
+	CombatDraws uint64
+
+	Levels []saveLevel
+}
+
+// saveLevel ... a level of the dungeon
+type saveLevel struct {
+	Depth  int
+	Height int
+	Width  int
+	Tiles  []Tile
+
+	Explored []bool
+
+	UpY   int
+	UpX   int
+	DownY int
+	DownX int
+
+	IsPopulatedWithCreatures bool
+
+	// Every creature on the level, including the player if present, in
+	// the order that they take their turns.
+	Creatures []saveCreature
+
+	// Items lying on the ground.
+	Items []saveItem
+
+	// The shop on the level, or nil if there is none.
+	Shop *Shop
+}
+
+// saveCreature ... a creature, along with everything it carries
+type saveCreature struct {
+	IsPlayer bool
+
+	Name    string
+	Species string
+	Y       int
+	X       int
+	Ch      rune
+
+	Hp    int
+	MaxHp int
+	Att   int
+	Def   int
+
+	Class *types.ClassTypeInfo
+
+	Strength     uint
+	Intelligence uint
+	Agility      uint
+	Wisdom       uint
+
+	Healrate    uint
+	Healcounter uint
+
+	Behaviour string
+	HomeY     int
+	HomeX     int
+
+	Level           int
+	Experience      int
+	ExperienceValue int
+
+	Mana      int
+	Cooldowns map[string]int
+
+	Gold int
+
+	Statuses []StatusEffect
+	OnHit    []types.HitEffect
+
+	Speed  int
+	Energy int
+
+	Inventory []saveItem
+
+	// Equipped items keyed by slot name, or nil for creatures that
+	// cannot equip anything.
+	Equipment map[string]saveItem
+}
+
+// saveItem ... an item, either on the ground or held by a creature
+type saveItem struct {
+	Name     string
+	Category string
+	Y        int
+	X        int
+	Ch       rune
+
+	CanEquip bool
+	IsBroken bool
+
+	DurabilityCurrent int
+	DurabilityMaximum int
+
+	PriceToPurchase int
+	PriceToSell     int
+	Weight          int
+
+	AttackIncrease  int
+	DefenceIncrease int
+
+	Effects  []types.ItemEffect
+	UseVerb  string
+	Quantity int
+
+	Unpaid bool
+}
+
+// SaveGame ... Handles a "save game to disk" event.
+/*
+ * @param     string    path of the save file
+ *
+ * @return    error     error message, if any
+ */
+func (g *Game) SaveGame(filename string) error {
+
+	if filename == "" || g.Player == nil || g.Dungeon == nil {
+		return fmt.Errorf("SaveGame() --> invalid input")
+	}
+
+	var body bytes.Buffer
+	if err := gob.NewEncoder(&body).Encode(g.toSave()); err != nil {
+		return err
+	}
+
+	summary := saveSummary{g.Player.name, "", g.Depth, g.Turns, time.Now()}
+	if g.Player.class != nil {
+		summary.Class = g.Player.class.Name
+	}
+
+	envelope := saveEnvelope{saveMagic, SaveVersion, summary,
+		crc32.ChecksumIEEE(body.Bytes()), body.Bytes()}
+
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		return gob.NewEncoder(w).Encode(envelope)
+	})
+}
+
+// writeFileAtomic ... write a file via a temporary file that is synced
+// and then renamed into place, so that a crash or full disk part way thru
+// never leaves a half written file behind.
+/*
+ * @param     string    path of the file
+ * @param     func      writes the contents of the file
+ *
+ * @return    error     error message, if any
+ */
+func writeFileAtomic(filename string, write func(io.Writer) error) error {
+
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+
+	file, err := os.CreateTemp(dir, base+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	// Clean up the temporary file, unless it was renamed into place.
+	renamed := false
+	defer func() {
+		if !renamed {
+			os.Remove(file.Name())
+		}
+	}()
+
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(file.Name(), filename); err != nil {
+		return err
+	}
+	renamed = true
+
+	// Sync the directory too, so that the rename itself survives a power
+	// cut; not every platform allows this, so errors are ignored.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+
+	return nil
+}
+
+// LoadGame ... handles a "load game from disk" event.
+/*
+ * @param     string    path of the save file
+ *
+ * @return    error     error message, if any
+ */
+func (g *Game) LoadGame(filename string) error {
+
+	if filename == "" {
+		return fmt.Errorf("LoadGame() --> invalid input")
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	save, err := decodeSave(data)
+	if err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+
+	return g.fromSave(save)
+}
+
+// decodeSave ... check and decode the contents of a save file, bringing
+// older versions up to date.
+/*
+ * @param     byte[]      contents of the save file
+ *
+ * @return    saveGame*   the decoded save
+ *            error       error message, if any
+ */
+func decodeSave(data []byte) (*saveGame, error) {
+
+	envelope, err := decodeEnvelope(data)
+	if err != nil {
+
+		// Files from before the save schema had no envelope at all.
+		if save, legacyErr := decodeLegacySave(data); legacyErr == nil {
+			return save, nil
+		}
+
+		return nil, err
+	}
+
+	if crc32.ChecksumIEEE(envelope.Body) != envelope.Checksum {
+		return nil, fmt.Errorf("save file is corrupt (checksum mismatch)")
+	}
+
+	return decodeSaveBody(envelope.Version, envelope.Body)
+}
+
+// decodeEnvelope ... decode and check the outer layer of a save file.
+/*
+ * @param     byte[]          contents of the save file
+ *
+ * @return    saveEnvelope*   the envelope
+ *            error           error message, if any
+ */
+func decodeEnvelope(data []byte) (*saveEnvelope, error) {
+
+	var envelope saveEnvelope
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&envelope)
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("save file is truncated or corrupt")
+	}
+	if err != nil || envelope.Magic != saveMagic {
+		return nil, fmt.Errorf("not a save file, or it is corrupt")
+	}
+
+	if envelope.Version > SaveVersion {
+		return nil, fmt.Errorf("saved by a newer version of the game "+
+			"(save version %d, this game supports up to %d)",
+			envelope.Version, SaveVersion)
+	}
+
+	return &envelope, nil
+}
+
+// readSaveSummary ... read the details of a save file for the load screen.
+/*
+ * @param     string         path of the save file
+ *
+ * @return    saveSummary    details of the save
+ *            error          error message, if any
+ */
+func readSaveSummary(filename string) (saveSummary, error) {
+
+	var summary saveSummary
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return summary, err
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return summary, err
+	}
+
+	// Newer saves carry a summary up front...
+	envelope, err := decodeEnvelope(data)
+	if err == nil && envelope.Summary.Name != "" {
+		return envelope.Summary, nil
+	}
+
+	// ...while older ones need to be decoded in full.
+	save, err := decodeSave(data)
+	if err != nil {
+		return summary, err
+	}
+
+	summary.Depth, summary.Turns, summary.SavedAt = save.Depth, save.Turns,
+		info.ModTime()
+
+	for _, level := range save.Levels {
+		for _, sc := range level.Creatures {
+			if sc.IsPlayer {
+				summary.Name = sc.Name
+				if sc.Class != nil {
+					summary.Class = sc.Class.Name
+				}
+			}
+		}
+	}
+
+	return summary, nil
+}
+
+// decodeSaveBody ... decode the body of a save of the given version.
+/*
+ * @param     int         save version
+ * @param     byte[]      encoded body
+ *
+ * @return    saveGame*   the decoded save, in the current schema
+ *            error       error message, if any
+ */
+func decodeSaveBody(version int, body []byte) (*saveGame, error) {
+
+	switch version {
+	case 2:
+		var save saveGame
+		if err := gob.NewDecoder(bytes.NewReader(body)).Decode(&save); err != nil {
+			return nil, fmt.Errorf("save file is corrupt: %v", err)
+		}
+		return &save, nil
+	}
+
+	return nil, fmt.Errorf("unsupported save version %d", version)
+}
+
+// toSave ... gather up the state of the game into the save schema.
+/*
+ * @return    saveGame*    state of the game
+ */
+func (g *Game) toSave() *saveGame {
+
+	aiDraws, combatDraws := g.rng.Draws()
+	save := &saveGame{g.Seed, g.Depth, g.Turns, g.ticks, aiDraws,
+		combatDraws, make([]saveLevel, 0)}
+
+	for depth := 1; depth <= MaxDepth; depth++ {
+
+		a, exists := g.Dungeon.Levels[depth]
+		if !exists {
+			continue
+		}
+
+		level := saveLevel{depth, a.Height, a.Width, a.Tiles, a.Explored,
+			a.UpY, a.UpX, a.DownY, a.DownX, a.IsPopulatedWithCreatures,
+			make([]saveCreature, 0, len(a.Creatures)),
+			make([]saveItem, 0, len(a.Items)), a.Shop}
+
+		for _, m := range a.Creatures {
+			level.Creatures = append(level.Creatures, m.toSave(m == g.Player))
+		}
+		for _, itm := range a.Items {
+			level.Items = append(level.Items, itm.toSave())
+		}
+
+		save.Levels = append(save.Levels, level)
+	}
+
+	return save
+}
+
+// fromSave ... replace the state of the game with that of a save,
+// relinking every creature and item to the level it is on.
+/*
+ * @param     saveGame*    state of the game
+ *
+ * @return    error        error message, if any
+ */
+func (g *Game) fromSave(save *saveGame) error {
+
+	dungeon := NewDungeon(save.Seed)
+	var player *Creature
+
+	for _, level := range save.Levels {
+
+		if level.Depth < 1 || level.Depth > MaxDepth ||
+			level.Height < 1 || level.Width < 1 ||
+			len(level.Tiles) != level.Height*level.Width {
+			return fmt.Errorf("save file is corrupt: bad level %d",
+				level.Depth)
+		}
+
+		a := &Area{Tiles: level.Tiles,
+			Creatures:                make([]*Creature, 0, len(level.Creatures)),
+			Items:                    make([]*Item, 0, len(level.Items)),
+			Height:                   level.Height,
+			Width:                    level.Width,
+			IsPopulatedWithCreatures: level.IsPopulatedWithCreatures,
+			Depth:                    level.Depth,
+			UpY:                      level.UpY,
+			UpX:                      level.UpX,
+			DownY:                    level.DownY,
+			DownX:                    level.DownX,
+			Explored:                 level.Explored,
+			Shop:                     level.Shop}
+
+		for _, sc := range level.Creatures {
+
+			m := sc.restore(a)
+			a.Creatures = append(a.Creatures, m)
+
+			if sc.IsPlayer {
+				if player != nil || level.Depth != save.Depth {
+					return fmt.Errorf("save file is corrupt: misplaced player")
+				}
+				player = m
+			}
+		}
+
+		for _, si := range level.Items {
+			a.Items = append(a.Items, si.restore(a))
+		}
+
+		dungeon.Levels[level.Depth] = a
+	}
+
+	if player == nil {
+		return fmt.Errorf("save file is corrupt: no player")
+	}
+
+	g.Seed = save.Seed
+	g.rng = NewRandomStreams(save.Seed)
+	g.rng.Resume(save.AIDraws, save.CombatDraws)
+	g.Dungeon = dungeon
+	g.Depth = save.Depth
+	g.Turns, g.ticks = save.Turns, save.Ticks
+	g.autosavedTurn = save.Turns
+	g.Area = dungeon.Levels[save.Depth]
+	g.Player = player
+	g.GroundItems = make([]*Item, 0)
+	g.groundMarked = make(map[*Item]bool)
+
+	// Levels migrated from older saves may have lost their creatures and
+	// items, so give them a fresh set.
+	for depth := 1; depth <= MaxDepth; depth++ {
+		if a, exists := dungeon.Levels[depth]; exists &&
+			!a.IsPopulatedWithCreatures {
+			g.rng.ForLevel(save.Seed, depth)
+			a.populateAreaWithCreatures(g.rng.Spawn)
+			a.populateAreaWithItems(g.rng.Spawn)
+		}
+	}
+
+	return nil
+}
+
+// toSave ... convert a creature into the save schema.
+/*
+ * @param     bool            whether or not this is the player
+ *
+ * @return    saveCreature    the creature
+ */
+func (m *Creature) toSave(isPlayer bool) saveCreature {
+
+	sc := saveCreature{isPlayer, m.name, m.species, m.Y, m.X, m.ch,
+		m.Hp, m.MaxHp, m.Att, m.Def, m.class, m.Strength, m.Intelligence,
+		m.Agility, m.Wisdom, m.Healrate, m.Healcounter, m.behaviour,
+		m.homeY, m.homeX, m.Level, m.Experience, m.experienceValue, m.Mana,
+		m.cooldowns, m.Gold, m.statuses, m.onHit, m.speed, m.energy,
+		make([]saveItem, 0, len(m.inventory)), nil}
+
+	for _, itm := range m.inventory {
+		sc.Inventory = append(sc.Inventory, itm.toSave())
+	}
+
+	if m.equipment != nil {
+		sc.Equipment = make(map[string]saveItem)
+		for _, name := range equipmentSlotNames {
+			if itm := *m.equipment.slot(name); itm != nil {
+				sc.Equipment[name] = itm.toSave()
+			}
+		}
+	}
+
+	return sc
+}
+
+// restore ... rebuild a creature from the save schema.
+/*
+ * @param     Area*        level the creature is on
+ *
+ * @return    Creature*    the creature
+ */
+func (sc *saveCreature) restore(a *Area) *Creature {
+
+	m := NewCreature(sc.Name, sc.Species, sc.Y, sc.X, sc.Ch, a,
+		make([]*Item, 0, len(sc.Inventory)), sc.Hp, sc.MaxHp, sc.Att, sc.Def,
+		sc.Class, sc.Strength, sc.Intelligence, sc.Agility, sc.Wisdom,
+		sc.Healrate, sc.Healcounter, sc.Behaviour, sc.ExperienceValue)
+	m.homeY, m.homeX = sc.HomeY, sc.HomeX
+	m.Level, m.Experience = sc.Level, sc.Experience
+	m.Mana, m.cooldowns = sc.Mana, sc.Cooldowns
+	m.Gold = sc.Gold
+	m.statuses, m.onHit = sc.Statuses, sc.OnHit
+	m.speed, m.energy = sc.Speed, sc.Energy
+
+	// Items being carried are not on any level.
+	for _, si := range sc.Inventory {
+		m.inventory = append(m.inventory, si.restore(nil))
+	}
+
+	if sc.Equipment != nil {
+		m.equipment = newEquipment(nil, nil, nil, nil, nil, nil)
+		for name, si := range sc.Equipment {
+			if slot := m.equipment.slot(name); slot != nil {
+				*slot = si.restore(nil)
+			}
+		}
+	}
+
+	// The player already knows how weighed down they are.
+	m.burden = m.Encumbrance()
+
+	return m
+}
+
+// toSave ... convert an item into the save schema.
+/*
+ * @return    saveItem    the item
+ */
+func (itm *Item) toSave() saveItem {
+	return saveItem{itm.name, itm.category, itm.Y, itm.X, itm.ch,
+		itm.canEquip, itm.isBroken, itm.durabilityCurrent,
+		itm.durabilityMaximum, itm.priceToPurchase, itm.priceToSell,
+		itm.weight, itm.attackIncrease, itm.defenceIncrease, itm.effects,
+		itm.useVerb, itm.quantity, itm.unpaid}
+}
+
+// restore ... rebuild an item from the save schema.
+/*
+ * @param     Area*    level the item lies on, or nil if carried
+ *
+ * @return    Item*    the item
+ */
+func (si *saveItem) restore(a *Area) *Item {
+
+	itm := NewItem(si.Name, si.Category, si.Y, si.X, si.Ch, a, si.CanEquip,
+		si.IsBroken, si.DurabilityCurrent, si.DurabilityMaximum,
+		si.PriceToPurchase, si.PriceToSell, si.Weight, si.AttackIncrease,
+		si.DefenceIncrease)
+
+	// Plain items carry no use verb, and single items no quantity.
+	itm.effects = si.Effects
+	if si.UseVerb != "" {
+		itm.useVerb = si.UseVerb
+	}
+	if si.Quantity > 1 {
+		itm.quantity = si.Quantity
+	}
+	itm.unpaid = si.Unpaid
+
+	return itm
+}
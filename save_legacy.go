@@ -0,0 +1,178 @@
+/*
+ * File: save_legacy.go
+ *
+ * Description: Migrates save files written before the versioned save
+ *              schema (version 1) forward to the current one.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// legacyGame ... the exported fields of the Game struct, as gob-encoded
+// by version 1 save files
+type legacyGame struct {
+	Player  *legacyCreature
+	Area    *legacyArea
+	Dungeon *legacyDungeon
+	Depth   int
+	Seed    int64
+}
+
+// legacyDungeon ... the exported fields of a version 1 Dungeon
+type legacyDungeon struct {
+	Levels map[int]*legacyArea
+	Seed   int64
+}
+
+// legacyArea ... the exported fields of a version 1 Area; creatures and
+// items are left out, since their names and appearance were never saved.
+type legacyArea struct {
+	Tiles  []Tile
+	Height int
+	Width  int
+
+	Depth int
+	UpY   int
+	UpX   int
+	DownY int
+	DownX int
+
+	Explored []bool
+}
+
+// legacyCreature ... the exported fields of a version 1 Creature
+type legacyCreature struct {
+	Y int
+	X int
+
+	Hp    int
+	MaxHp int
+	Att   int
+	Def   int
+
+	Strength     uint
+	Intelligence uint
+	Agility      uint
+	Wisdom       uint
+
+	Healrate    uint
+	Healcounter uint
+}
+
+// decodeLegacySave ... decode a version 1 save file, and migrate it.
+/*
+ * @param     byte[]      contents of the save file
+ *
+ * @return    saveGame*   the migrated save
+ *            error       error message, if any
+ */
+func decodeLegacySave(data []byte) (*saveGame, error) {
+
+	var legacy legacyGame
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&legacy); err != nil {
+		return nil, err
+	}
+
+	return legacy.migrate()
+}
+
+// migrate ... convert a version 1 save into the current schema.
+//
+// Version 1 lost the name, class and equipment of the player, and the
+// name and appearance of every creature and item. The player is given
+// the name and class picked at the menu, the default ones if none, and
+// each level loses its items and is given a fresh set of creatures.
+/*
+ * @return    saveGame*    the migrated save
+ *            error        error message, if any
+ */
+func (legacy *legacyGame) migrate() (*saveGame, error) {
+
+	if legacy.Player == nil || legacy.Area == nil {
+		return nil, fmt.Errorf("not a save file")
+	}
+
+	// The earliest saves had a single level and no seed.
+	levels := map[int]*legacyArea{1: legacy.Area}
+	depth := 1
+	if legacy.Dungeon != nil && len(legacy.Dungeon.Levels) > 0 {
+		levels, depth = legacy.Dungeon.Levels, legacy.Depth
+	}
+
+	seed := legacy.Seed
+	if seed == 0 {
+		seed = NewSeed()
+	}
+
+	save := &saveGame{seed, depth, 0, 0, 0, 0, make([]saveLevel, 0, len(levels))}
+
+	for d := 1; d <= MaxDepth; d++ {
+
+		a, exists := levels[d]
+		if !exists || a == nil {
+			continue
+		}
+
+		level := saveLevel{Depth: d, Height: a.Height, Width: a.Width,
+			Tiles: a.Tiles, Explored: a.Explored, UpY: a.UpY, UpX: a.UpX,
+			DownY: a.DownY, DownX: a.DownX,
+			Creatures: make([]saveCreature, 0),
+			Items:     make([]saveItem, 0)}
+
+		// Levels from before the dungeon had staircases need some, or
+		// the player could never leave.
+		if a.Depth == 0 {
+			stairs := &Area{Tiles: level.Tiles, Height: level.Height,
+				Width: level.Width, Depth: d}
+			stairs.placeStairs(legacy.Player.Y, legacy.Player.X,
+				NewRandomStreams(seed).Spawn)
+			level.UpY, level.UpX = stairs.UpY, stairs.UpX
+			level.DownY, level.DownX = stairs.DownY, stairs.DownX
+		}
+
+		if d == depth {
+			level.Creatures = append(level.Creatures, legacy.Player.migrate())
+		}
+
+		save.Levels = append(save.Levels, level)
+	}
+
+	return save, nil
+}
+
+// migrate ... convert a version 1 player-character into the current
+// schema.
+/*
+ * @return    saveCreature    the player
+ */
+func (p *legacyCreature) migrate() saveCreature {
+
+	name := PlayerName
+	if name == "" {
+		name = "Anonymous"
+	}
+
+	class := PlayerClass
+	if class == nil {
+		defaultClass := GlobalClassTypeInfoMap["1"]
+		class = &defaultClass
+	}
+
+	// Spellcasters start out with a full pool of mana.
+	mana := (&Creature{class: class, Intelligence: p.Intelligence,
+		Wisdom: p.Wisdom}).maxMana()
+
+	return saveCreature{IsPlayer: true, Name: name, Species: "player",
+		Y: p.Y, X: p.X, Ch: '@', Hp: p.Hp, MaxHp: p.MaxHp, Att: p.Att,
+		Def: p.Def, Class: class, Strength: p.Strength,
+		Intelligence: p.Intelligence, Agility: p.Agility, Wisdom: p.Wisdom,
+		Healrate: p.Healrate, Healcounter: p.Healcounter, HomeY: p.Y,
+		HomeX: p.X, Level: 1, Mana: mana, Speed: normalSpeed,
+		Energy: turnEnergy, Inventory: make([]saveItem, 0),
+		Equipment: make(map[string]saveItem)}
+}
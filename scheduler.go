@@ -0,0 +1,162 @@
+/*
+ * File: scheduler.go
+ *
+ * Description: Decides who acts when. Every creature builds up energy in
+ *              proportion to its speed, and acts once it has enough;
+ *              each action then costs energy according to how long it
+ *              takes. Once per turn, healing, status effects and the like
+ *              take their course.
+ */
+
+package main
+
+import "github.com/rbisewski/go_roguelike/types"
+
+// turnEnergy ... energy a creature needs before it can act, which is also
+// the energy an ordinary creature builds up over a single turn
+const turnEnergy = 100
+
+// normalSpeed ... speed of an ordinary creature, including the player
+const normalSpeed = 100
+
+// ticksPerTurn ... number of steps each turn is divided into; a creature
+// gains a share of its speed as energy on every one of them
+const ticksPerTurn = 10
+
+// Energy spent by each kind of action; an action costing turnEnergy takes
+// an ordinary creature a whole turn.
+const (
+	actionMove    = 100
+	actionAttack  = 120
+	actionRest    = 100
+	actionUseItem = 150
+	actionAbility = 120
+	actionEquip   = 150
+	actionPickUp  = 50
+	actionDrop    = 50
+)
+
+// spend ... use up energy on an action.
+/*
+ * @param     int    energy the action costs, e.g. actionMove
+ *
+ * @return    none
+ */
+func (m *Creature) spend(cost int) {
+	m.energy -= cost
+}
+
+// currentSpeed ... speed of the creature, after haste and slow.
+/*
+ * @return    int    speed, where normalSpeed is ordinary
+ */
+func (m *Creature) currentSpeed() int {
+
+	speed := m.speed
+	if speed < 1 {
+		speed = normalSpeed
+	}
+
+	switch {
+	case m.hasStatus(types.StatusHaste):
+		speed = speed * 3 / 2
+	case m.hasStatus(types.StatusSlow):
+		speed = speed / 2
+	}
+
+	return speed
+}
+
+// advanceTime ... let time pass until the player has the energy to act
+// again, with every other creature on the level acting as often as its
+// speed allows in the meantime. Nothing happens if the player did not
+// spend any energy, e.g. by walking into a wall.
+/*
+ * @return    none
+ */
+func (g *Game) advanceTime() {
+
+	// Walking out of a shop with unpaid wares is stealing.
+	g.checkShoplifting()
+
+	for g.Player.energy < turnEnergy && !g.state.Quiting() {
+		g.tick()
+	}
+}
+
+// tick ... advance time by a single step, giving every creature of the
+// level its share of energy and letting those with enough of it act.
+/*
+ * @return    none
+ */
+func (g *Game) tick() {
+
+	g.ticks++
+	if g.ticks%ticksPerTurn == 0 {
+		g.passTurn()
+		if g.state.Quiting() {
+			return
+		}
+	}
+
+	// Note which tiles are free, so that monsters path around each other.
+	grid := newAreaGrid(g.Area)
+
+	for _, m := range append([]*Creature(nil), g.Area.Creatures...) {
+
+		// Creatures killed earlier in the tick are gone already.
+		if m.Hp <= 0 {
+			continue
+		}
+
+		m.energy += m.currentSpeed() * turnEnergy / (normalSpeed *
+			ticksPerTurn)
+
+		// The player has no need for AI as the human controls it.
+		if m == g.Player {
+			continue
+		}
+
+		for m.energy >= turnEnergy && m.Hp > 0 && !g.state.Quiting() {
+
+			// Stunned monsters can do nothing at all, and monsters
+			// that find nothing to do wait instead.
+			energy := m.energy
+			if !m.hasStatus(types.StatusStun) {
+				g.actMonster(grid, m)
+			}
+			if m.energy == energy {
+				m.spend(actionRest)
+			}
+		}
+	}
+}
+
+// passTurn ... let another turn go by, during which wounds heal, status
+// effects take their toll and abilities recover.
+/*
+ * @return    none
+ */
+func (g *Game) passTurn() {
+
+	g.Turns++
+
+	// Abilities of the player recover as time passes.
+	g.Player.tickAbilities(g.Turns)
+
+	// Every creature of the level heals and suffers its status effects,
+	// which may be the end of some of them, the player included.
+	for _, m := range append([]*Creature(nil), g.Area.Creatures...) {
+		m.tickHealing()
+		m.tickStatuses()
+	}
+}
+
+// Rest ... have the player wait where they are for a turn.
+/*
+ * @return    none
+ */
+func (g *Game) Rest() {
+	g.Player.spend(actionRest)
+	g.advanceTime()
+}
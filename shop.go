@@ -0,0 +1,726 @@
+/*
+ * File: shop.go
+ *
+ * Description: Gold, and the shops where it is spent; each shop is a room
+ *              of wares minded by a shopkeeper, who buys, sells and
+ *              repairs at the prices of the items and turns on thieves.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/rbisewski/go_roguelike/types"
+)
+
+// goldCategory ... category of the piles of gold left on the ground
+const goldCategory = "gold"
+
+// shopChance ... percentage chance of a level having a shop
+const shopChance = 50
+
+// Size of the inside of a shop, not counting its walls.
+const (
+	shopHeight = 5
+	shopWidth  = 7
+)
+
+// shopWares ... number of items a shop starts out with
+const shopWares = 10
+
+// shopkeeperType ... creature type that minds every shop
+const shopkeeperType = "shopkeeper"
+
+// shopScreens ... the screens of a shop, in the order the left and right
+// arrows move between them
+var shopScreens = []GameState{"shop_buy", "shop_sell", "shop_repair"}
+
+// Shop ... Structure to hold where a shop is on its level.
+type Shop struct {
+
+	// Bounds of the floor of the shop, inclusive.
+	Top    int
+	Left   int
+	Bottom int
+	Right  int
+
+	// Gap in the walls of the shop, which is the only way in or out.
+	DoorY int
+	DoorX int
+}
+
+// contains ... whether the given tile is on the floor of the shop.
+/*
+ * @param     int     y-value
+ * @param     int     x-value
+ *
+ * @return    bool    whether or not the tile is in the shop
+ */
+func (s *Shop) contains(y, x int) bool {
+	return s != nil && y >= s.Top && y <= s.Bottom && x >= s.Left &&
+		x <= s.Right
+}
+
+// inShop ... whether the given tile is on the floor of the shop of the
+// level, if it has one.
+/*
+ * @param     int     y-value
+ * @param     int     x-value
+ *
+ * @return    bool    whether or not the tile is in a shop
+ */
+func (a *Area) inShop(y, x int) bool {
+	return a != nil && a.Shop.contains(y, x)
+}
+
+// placeShop ... build a walled shop somewhere on the level, with a door
+// onto the open part of it, and stock it with a shopkeeper and wares. A
+// spot is only kept if the staircases can still be reached around it.
+/*
+ * @param     rand.Rand*   spawning random number stream
+ *
+ * @return    bool         whether or not a shop was placed
+ */
+func (a *Area) placeShop(r *rand.Rand) bool {
+
+	if a == nil || r == nil {
+		DebugLog(&G, fmt.Sprintf("placeShop() --> invalid input"))
+		return false
+	}
+
+	// Height and width of the shop, including its walls.
+	h, w := shopHeight+2, shopWidth+2
+	if a.Height < h+4 || a.Width < w+4 {
+		return false
+	}
+
+	reachable := a.reachableGrid()
+
+	for attempts := 0; attempts < 200; attempts++ {
+
+		top := 2 + r.Intn(a.Height-h-3)
+		left := 2 + r.Intn(a.Width-w-3)
+		walls := &Shop{top, left, top + h - 1, left + w - 1, 0, 0}
+
+		if walls.contains(a.UpY, a.UpX) || walls.contains(a.DownY, a.DownX) {
+			continue
+		}
+
+		// The door is in the middle of one of the four walls, leading out
+		// onto a tile the player can reach.
+		doors := [][4]int{
+			{top, left + w/2, -1, 0},
+			{top + h - 1, left + w/2, 1, 0},
+			{top + h/2, left, 0, -1},
+			{top + h/2, left + w - 1, 0, 1},
+		}
+		first := r.Intn(len(doors))
+		for i := range doors {
+
+			door := doors[(first+i)%len(doors)]
+			outY, outX := door[0]+door[2], door[1]+door[3]
+			if !reachable[outX+outY*a.Width] {
+				continue
+			}
+
+			// Try the shop here, and knock it back down again if its walls
+			// cut the way down off.
+			tiles := append([]Tile(nil), a.Tiles...)
+			a.Shop = &Shop{top + 1, left + 1, top + h - 2, left + w - 2,
+				door[0], door[1]}
+			a.carveShop()
+
+			after := a.reachableGrid()
+			if !after[a.DownX+a.DownY*a.Width] ||
+				!after[door[1]+door[0]*a.Width] {
+				a.Tiles, a.Shop = tiles, nil
+				break
+			}
+
+			a.stockShop(door[0]-door[2], door[1]-door[3], r)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// reachableGrid ... which tiles of the level can be reached from the up
+// staircase.
+/*
+ * @return    bool[]    whether or not each tile can be reached
+ */
+func (a *Area) reachableGrid() []bool {
+
+	reachable := make([]bool, len(a.Tiles))
+	for _, tile := range a.reachableTiles(a.UpY, a.UpX) {
+		reachable[tile] = true
+	}
+
+	return reachable
+}
+
+// carveShop ... lay the walls, floor and door of the shop of the level.
+/*
+ * @return    none
+ */
+func (a *Area) carveShop() {
+
+	s := a.Shop
+
+	for y := s.Top - 1; y <= s.Bottom+1; y++ {
+		for x := s.Left - 1; x <= s.Right+1; x++ {
+			if s.contains(y, x) {
+				a.Tiles[x+y*a.Width] = Tile{'.', false, false}
+			} else {
+				a.Tiles[x+y*a.Width] = Tile{'#', true, true}
+			}
+		}
+	}
+
+	a.Tiles[s.DoorX+s.DoorY*a.Width] = Tile{'.', false, false}
+}
+
+// stockShop ... fill the shop with a shopkeeper, who stands beside the way
+// in, and wares.
+/*
+ * @param     int          y-value of the tile just inside the door
+ * @param     int          x-value of the tile just inside the door
+ * @param     rand.Rand*   spawning random number stream
+ *
+ * @return    none
+ */
+func (a *Area) stockShop(entryY, entryX int, r *rand.Rand) {
+
+	s := a.Shop
+
+	// The shopkeeper stands to one side of the way in, so as not to block
+	// it.
+	keeperY, keeperX := entryY, entryX+1
+	if entryY == s.DoorY {
+		keeperY, keeperX = entryY+1, entryX
+	}
+	spawnCreatureToArray(shopkeeperType, keeperX, keeperY, a, r)
+
+	placed := 0
+	for attempts := 0; placed < shopWares && attempts < 100; attempts++ {
+
+		y := s.Top + r.Intn(shopHeight)
+		x := s.Left + r.Intn(shopWidth)
+
+		_, _, hasCreature, hasItems := a.GetTileInfo(y, x)
+		if (y == entryY && x == entryX) || hasCreature != nil ||
+			len(hasItems) > 0 {
+			continue
+		}
+
+		name := pickItemType(a.Depth, r)
+		if name == "" || !spawnItemToArray(name, x, y, a) {
+			break
+		}
+
+		a.Items[len(a.Items)-1].unpaid = true
+		placed++
+	}
+}
+
+// shopkeeper ... the shopkeeper of the level, if there is one still alive.
+/*
+ * @return    Creature*    the shopkeeper, or nil if none
+ */
+func (a *Area) shopkeeper() *Creature {
+
+	for _, m := range a.Creatures {
+		if m.species == shopkeeperType && m.Hp > 0 {
+			return m
+		}
+	}
+
+	return nil
+}
+
+// freeShopTile ... a tile of the shop with nothing on it, to put an item
+// the shopkeeper has bought.
+/*
+ * @return    int     y-value
+ *            int     x-value
+ *            bool    false if the shop is full
+ */
+func (a *Area) freeShopTile() (int, int, bool) {
+
+	s := a.Shop
+	for y := s.Top; y <= s.Bottom; y++ {
+		for x := s.Left; x <= s.Right; x++ {
+			_, _, hasCreature, hasItems := a.GetTileInfo(y, x)
+			if hasCreature == nil && len(hasItems) == 0 {
+				return y, x, true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// isShopkeeper ... whether the creature is a shopkeeper willing to trade.
+/*
+ * @return    bool    true if the creature is a peaceful shopkeeper
+ */
+func (m *Creature) isShopkeeper() bool {
+	return m != nil && m.species == shopkeeperType &&
+		m.behaviour == types.BehaviourShopkeeper
+}
+
+// angerShopkeeper ... turn a shopkeeper on the player for good; one who
+// is already hostile stays so without a word.
+/*
+ * @return    none
+ */
+func (m *Creature) angerShopkeeper() {
+
+	if m.behaviour != types.BehaviourShopkeeper {
+		return
+	}
+
+	m.behaviour = types.BehaviourAggressive
+	MessageLog.log(fmt.Sprintf("The %s is furious!", m.name))
+}
+
+// newGoldPile ... a pile of gold lying on the ground.
+/*
+ * @param     int      number of gold coins
+ * @param     int      y-value
+ * @param     int      x-value
+ * @param     Area*    level the gold lies on
+ *
+ * @return    Item*    the pile of gold
+ */
+func newGoldPile(amount, y, x int, a *Area) *Item {
+
+	itm := NewItem("Gold", goldCategory, y, x, '$', a, false, false, 0, 0,
+		0, 0, 0, 0, 0)
+	itm.quantity = amount
+
+	return itm
+}
+
+// conditionPrice ... price of an item in its current condition; worn items
+// are worth less, in proportion to the durability they have left.
+/*
+ * @param     int    price of the item in perfect condition
+ *
+ * @return    int    price of the item as it is
+ */
+func (itm *Item) conditionPrice(price int) int {
+
+	if itm.durabilityMaximum > 0 {
+		price = price * itm.durabilityCurrent / itm.durabilityMaximum
+	}
+
+	return price * itm.quantity
+}
+
+// buyPrice ... gold the shopkeeper asks for the item, or the whole stack.
+/*
+ * @return    int    price in gold
+ */
+func (itm *Item) buyPrice() int {
+	return Max(itm.conditionPrice(itm.priceToPurchase), 1)
+}
+
+// sellPrice ... gold the shopkeeper offers for the item, or the whole
+// stack.
+/*
+ * @return    int    price in gold
+ */
+func (itm *Item) sellPrice() int {
+	return itm.conditionPrice(itm.priceToSell)
+}
+
+// pricedEntry ... list entry for an item with its price alongside.
+/*
+ * @param     Item*        item to list
+ * @param     int          price of the item
+ *
+ * @return    ListEntry    entry for the item
+ */
+func pricedEntry(itm *Item, price int) ListEntry {
+
+	entry := itemEntry(itm)
+	entry.Label = priceLabel(itm, price)
+
+	return entry
+}
+
+// priceLabel ... name of an item with its price alongside.
+/*
+ * @param     Item*     item to label
+ * @param     int       price of the item
+ *
+ * @return    string    e.g. "Sword                 40g"
+ */
+func priceLabel(itm *Item, price int) string {
+	return fmt.Sprintf("%-18s %5dg", truncateString(itm.displayName(), 18),
+		price)
+}
+
+// openShop ... start trading with a shopkeeper.
+/*
+ * @param     Creature*    the shopkeeper
+ *
+ * @return    none
+ */
+func (g *Game) openShop(keeper *Creature) {
+
+	MessageLog.log(fmt.Sprintf("The %s greets you.", keeper.name))
+
+	g.state = "shop_buy"
+	DrawShopUI(g)
+}
+
+// shopWaresOf ... every ware of the shop not yet paid for, whether carried
+// by the player or still lying in the shop.
+/*
+ * @param     Game*     pointer to the current game object
+ *
+ * @return    Item[]    the unpaid wares
+ */
+func shopWaresOf(g *Game) []*Item {
+
+	wares := make([]*Item, 0)
+	for _, itm := range g.Player.inventory {
+		if itm.unpaid {
+			wares = append(wares, itm)
+		}
+	}
+	for _, itm := range g.Area.Items {
+		if itm.unpaid && g.Area.inShop(itm.Y, itm.X) {
+			wares = append(wares, itm)
+		}
+	}
+
+	return wares
+}
+
+// shopView ... gather what is for sale, what the player could sell, or
+// what they could have repaired, into the list of the shop screen.
+/*
+ * @param     Game*        pointer to the current game object
+ *
+ * @return    ListView*    list of the shop screen
+ *            Item[]       items listed, in the order given to the list
+ */
+func shopView(g *Game) (*ListView, []*Item) {
+
+	keeperGold := 0
+	if keeper := g.Area.shopkeeper(); keeper != nil {
+		keeperGold = keeper.Gold
+	}
+
+	view := g.listView(string(g.state), true)
+	items := make([]*Item, 0)
+	entries := make([]ListEntry, 0)
+
+	// What the letters do, and the rest of the footer.
+	var action string
+	var footer []string
+
+	if g.state == "shop_sell" {
+		for _, itm := range g.Player.inventory {
+			if !itm.unpaid {
+				items = append(items, itm)
+				entries = append(entries, pricedEntry(itm, itm.sellPrice()))
+			}
+		}
+		view.Title, view.Empty = "Sell to the Shopkeeper", "Nothing to sell."
+		action, footer = " sell", []string{"Left buy, Right repair",
+			fmt.Sprintf("Shopkeeper has %d gold", keeperGold)}
+
+	} else if g.state == "shop_repair" {
+		for _, itm := range g.Player.wornItems() {
+			items = append(items, itm)
+			entries = append(entries, pricedEntry(itm, itm.repairPrice()))
+		}
+		view.Title, view.Empty = "Repairs", "Nothing needs repair."
+		action, footer = " repair, Left to sell", []string{
+			fmt.Sprintf("You have %d gold", g.Player.Gold)}
+
+	} else {
+		for _, itm := range shopWaresOf(g) {
+			items = append(items, itm)
+			entries = append(entries, pricedEntry(itm, itm.buyPrice()))
+		}
+		view.Title, view.Empty = "Wares for Sale", "Sold out."
+		action, footer = " buy, Right to sell", []string{
+			fmt.Sprintf("You have %d gold", g.Player.Gold)}
+	}
+
+	view.SetEntries(entries)
+
+	view.Footer = append([]string{view.LetterHint() + action}, footer...)
+
+	return view, items
+}
+
+// BuyShopItem ... pay for a ware picked from the shop screen.
+/*
+ * @param     Game*    pointer to the current game object
+ * @param     string   the given key that was pressed
+ *
+ * @return    error    error message, if any
+ */
+func BuyShopItem(g *Game, key string) error {
+
+	if g == nil || g.Player == nil || len(key) < 1 {
+		return fmt.Errorf("BuyShopItem() --> invalid input")
+	}
+
+	view, items := shopView(g)
+	index, ok := view.Selected(key)
+	if !ok {
+		return nil
+	}
+
+	itm, keeper := items[index], g.Area.shopkeeper()
+	if keeper == nil {
+		return fmt.Errorf("BuyShopItem() --> shop has no shopkeeper")
+	}
+
+	price := itm.buyPrice()
+	if g.Player.Gold < price {
+		MessageLog.log(fmt.Sprintf("You cannot afford the %s.",
+			itm.displayName()))
+		return nil
+	}
+
+	g.Player.Gold -= price
+	keeper.Gold += price
+
+	// Take the ware off the shelf, or out of the backpack so that it can
+	// join any stack of the same items already paid for.
+	if itm.area != nil {
+		g.Area.removeItem(itm)
+	} else {
+		g.Player.removeFromInventory(itm)
+	}
+	itm.unpaid = false
+	g.Player.addToInventory(itm)
+
+	MessageLog.log(fmt.Sprintf("You buy the %s for %d gold.",
+		itm.displayName(), price))
+
+	return nil
+}
+
+// SellShopItem ... sell an item picked from the shop screen, which the
+// shopkeeper then puts out for sale.
+/*
+ * @param     Game*    pointer to the current game object
+ * @param     string   the given key that was pressed
+ *
+ * @return    error    error message, if any
+ */
+func SellShopItem(g *Game, key string) error {
+
+	if g == nil || g.Player == nil || len(key) < 1 {
+		return fmt.Errorf("SellShopItem() --> invalid input")
+	}
+
+	view, items := shopView(g)
+	index, ok := view.Selected(key)
+	if !ok {
+		return nil
+	}
+
+	itm, keeper := items[index], g.Area.shopkeeper()
+	if keeper == nil {
+		return fmt.Errorf("SellShopItem() --> shop has no shopkeeper")
+	}
+
+	price := itm.sellPrice()
+	switch {
+	case itm.isBroken:
+		MessageLog.log(fmt.Sprintf("The %s will not buy a broken %s.",
+			keeper.name, itm.name))
+		return nil
+	case price < 1:
+		MessageLog.log(fmt.Sprintf("The %s has no use for the %s.",
+			keeper.name, itm.displayName()))
+		return nil
+	case keeper.Gold < price:
+		MessageLog.log(fmt.Sprintf("The %s cannot afford the %s.",
+			keeper.name, itm.displayName()))
+		return nil
+	}
+
+	g.Player.removeFromInventory(itm)
+	g.Player.Gold += price
+	keeper.Gold -= price
+
+	// Put the item out on the floor of the shop, or at the feet of the
+	// shopkeeper if there is no room left.
+	y, x, free := g.Area.freeShopTile()
+	if !free {
+		y, x = keeper.Y, keeper.X
+	}
+	itm.Y, itm.X, itm.area, itm.unpaid = y, x, g.Area, true
+	g.Area.Items = append(g.Area.Items, itm)
+
+	MessageLog.log(fmt.Sprintf("You sell the %s for %d gold.",
+		itm.displayName(), price))
+
+	return nil
+}
+
+// RepairShopItem ... pay the shopkeeper to restore an item picked from the
+// shop screen to perfect condition.
+/*
+ * @param     Game*    pointer to the current game object
+ * @param     string   the given key that was pressed
+ *
+ * @return    error    error message, if any
+ */
+func RepairShopItem(g *Game, key string) error {
+
+	if g == nil || g.Player == nil || len(key) < 1 {
+		return fmt.Errorf("RepairShopItem() --> invalid input")
+	}
+
+	view, items := shopView(g)
+	index, ok := view.Selected(key)
+	if !ok {
+		return nil
+	}
+
+	itm, keeper := items[index], g.Area.shopkeeper()
+	if keeper == nil {
+		return fmt.Errorf("RepairShopItem() --> shop has no shopkeeper")
+	}
+
+	price := itm.repairPrice()
+	if g.Player.Gold < price {
+		MessageLog.log(fmt.Sprintf("You cannot afford to repair the %s.",
+			itm.name))
+		return nil
+	}
+
+	g.Player.Gold -= price
+	keeper.Gold += price
+	itm.adjustDurability(itm.durabilityMaximum - itm.durabilityCurrent)
+
+	MessageLog.log(fmt.Sprintf("The %s repairs the %s for %d gold.",
+		keeper.name, itm.name, price))
+
+	return nil
+}
+
+// DrawShopUI ... display the shop screen, for buying, selling or repairs.
+/*
+ * @param     Game*    pointer to the current game object
+ *
+ * @return    none
+ */
+func DrawShopUI(g *Game) {
+
+	if g == nil || g.Player == nil {
+		return
+	}
+
+	view, _ := shopView(g)
+	view.Draw()
+}
+
+// shopInput ... handle a key pressed on the shop screen.
+/*
+ * @param     string    key pressed
+ *
+ * @return    bool      whether or not the key was dealt with
+ */
+func (g *Game) shopInput(key string) bool {
+
+	if g.state != "shop_buy" && g.state != "shop_sell" &&
+		g.state != "shop_repair" {
+		return false
+	}
+
+	view, _ := shopView(g)
+
+	current := 0
+	for i, name := range shopScreens {
+		if name == g.state {
+			current = i
+		}
+	}
+
+	switch keyAsString := fmt.Sprintf("%x", key); {
+
+	// ESC stops trading.
+	case keyAsString == "1b":
+		g.state = "playing"
+		return true
+
+	// Left and right arrows move between buying, selling and repairs.
+	case keyAsString == "c484" || keyAsString == "c485":
+		if keyAsString == "c484" && current > 0 {
+			current--
+		} else if keyAsString == "c485" && current < len(shopScreens)-1 {
+			current++
+		}
+		g.state = shopScreens[current]
+
+	// Paging, sorting and filtering.
+	case view.HandleKey(key):
+
+	case g.state == "shop_buy":
+		if err := BuyShopItem(g, key); err != nil {
+			DebugLog(g, err.Error())
+		}
+
+	case g.state == "shop_sell":
+		if err := SellShopItem(g, key); err != nil {
+			DebugLog(g, err.Error())
+		}
+
+	default:
+		if err := RepairShopItem(g, key); err != nil {
+			DebugLog(g, err.Error())
+		}
+	}
+
+	DrawShopUI(g)
+
+	return true
+}
+
+// checkShoplifting ... once the player is out of the shop, any wares they
+// are still carrying have been stolen, and the shopkeeper turns on them.
+/*
+ * @return    none
+ */
+func (g *Game) checkShoplifting() {
+
+	if g.Area.inShop(g.Player.Y, g.Player.X) {
+		return
+	}
+
+	// The wares stop being unpaid even when there is no live shopkeeper
+	// on the level. This is on purpose: with the keeper dead there is
+	// nobody left to pay, so whatever the player carries out is theirs.
+	stolen := false
+	for _, itm := range g.Player.inventory {
+		if itm.unpaid {
+			itm.unpaid, stolen = false, true
+		}
+	}
+
+	if !stolen {
+		return
+	}
+
+	if keeper := g.Area.shopkeeper(); keeper.isShopkeeper() {
+		MessageLog.log("You leave the shop without paying!")
+		keeper.angerShopkeeper()
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * File: shop_test.go
+ *
+ * Description: Checks that shop wares cannot leave the shop, or be used
+ *              up, without being paid for.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/rbisewski/go_roguelike/types"
+)
+
+// shopGame ... a game on a small open level with a shop in one corner,
+// and the player standing inside it beside the shopkeeper
+func shopGame(t *testing.T) (*Game, *Creature) {
+
+	t.Helper()
+
+	if err := LoadTypes(""); err != nil {
+		t.Fatal(err)
+	}
+	Display = NewFrameBuffer(50, 160)
+
+	h, w := 12, 20
+	a := &Area{Tiles: make([]Tile, h*w), Height: h, Width: w, Depth: 1,
+		visible: make([]bool, h*w)}
+	for i := range a.Tiles {
+		a.Tiles[i] = Tile{'.', false, false}
+		a.visible[i] = true
+	}
+	a.Shop = &Shop{1, 1, 5, 7, 6, 4}
+
+	player := NewCreatureWithEquipment("Tess", "player", 3, 3, '@', a,
+		make([]*Item, 0), 30, 30, 10, 5, nil, 10, 10, 10, 10, 10, 0)
+	keeper := NewCreatureWithEquipment("shopkeeper", shopkeeperType, 4, 4,
+		'@', a, make([]*Item, 0), 30, 30, 10, 5, nil, 10, 10, 10, 10, 10, 0)
+	keeper.behaviour = types.BehaviourShopkeeper
+	a.Creatures = append(a.Creatures, player, keeper)
+
+	g := &Game{Player: player, Area: a, Depth: 1,
+		rng: NewRandomStreams(1234)}
+
+	return g, keeper
+}
+
+// unpaidWare ... an item of the given type from the shop, picked up by
+// the player but not yet paid for
+func unpaidWare(t *testing.T, g *Game, key string) *Item {
+
+	t.Helper()
+
+	itm := NewItemFromType(key, g.Player.Y, g.Player.X, nil)
+	if itm == nil {
+		t.Fatalf("no item type %q", key)
+	}
+	itm.unpaid = true
+	g.Player.addToInventory(itm)
+
+	return itm
+}
+
+func TestLeaveShopWithEquippedWares(t *testing.T) {
+
+	g, keeper := shopGame(t)
+	sword := unpaidWare(t, g, "sword")
+
+	if g.Player.equip(sword) {
+		t.Fatal("equip() of an unpaid sword succeeded")
+	}
+	if len(g.Player.equippedItems()) != 0 || len(g.Player.inventory) != 1 {
+		t.Fatal("unpaid sword left the inventory")
+	}
+
+	// Walk out of the shop with the sword.
+	g.Player.Y, g.Player.X = 9, 4
+	g.checkShoplifting()
+
+	if keeper.behaviour != types.BehaviourAggressive {
+		t.Errorf("shopkeeper behaviour = %q, want aggressive",
+			keeper.behaviour)
+	}
+	if sword.unpaid {
+		t.Error("stolen sword is still unpaid")
+	}
+}
+
+func TestUseUnpaidWares(t *testing.T) {
+
+	g, keeper := shopGame(t)
+	scroll := unpaidWare(t, g, "teleport_scroll")
+
+	if g.Player.useItem(g, scroll) {
+		t.Fatal("useItem() of an unpaid scroll succeeded")
+	}
+	if g.Player.Y != 3 || g.Player.X != 3 || len(g.Player.inventory) != 1 {
+		t.Error("unpaid scroll was read")
+	}
+	if keeper.behaviour != types.BehaviourShopkeeper {
+		t.Errorf("shopkeeper behaviour = %q, want shopkeeper",
+			keeper.behaviour)
+	}
+}
+
+func TestSpellsPassOverShopkeeper(t *testing.T) {
+
+	g, keeper := shopGame(t)
+
+	if target := nearestVisibleTarget(g.Player); target != nil {
+		t.Fatalf("nearestVisibleTarget() = %s, want nil", target.name)
+	}
+
+	// Once hostile, the shopkeeper is fair game.
+	keeper.angerShopkeeper()
+	if target := nearestVisibleTarget(g.Player); target != keeper {
+		t.Errorf("nearestVisibleTarget() = %v, want the shopkeeper", target)
+	}
+}
@@ -0,0 +1,246 @@
+/*
+ * File: slots.go
+ *
+ * Description: Handles the save slots, one per character, which are kept
+ *              in the XDG data directory of the user.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// saveExtension ... file extension of every save slot
+const saveExtension = ".sav"
+
+// legacySaveFile ... file that games were saved to before there were
+// save slots, in the working directory
+const legacySaveFile = "player.sav"
+
+// SaveSlot ... Structure to hold the details of a saved game.
+type SaveSlot struct {
+
+	// Name of the slot, which is the file name less the extension.
+	Name string
+
+	// Full path of the save file.
+	Path string
+
+	// Details shown on the load screen.
+	Character string
+	Class     string
+	Depth     int
+	Turns     int
+	SavedAt   time.Time
+
+	// Why the save could not be read, if it could not.
+	Err error
+}
+
+// SaveDir ... directory where the save slots are kept; this follows the
+// XDG base directory spec, i.e. $XDG_DATA_HOME/go-roguelike/saves or
+// ~/.local/share/go-roguelike/saves by default.
+/*
+ * @return    string    path of the directory
+ *            error     error message, if any
+ */
+func SaveDir() (string, error) {
+
+	base := os.Getenv("XDG_DATA_HOME")
+
+	// The spec says relative paths are invalid and should be ignored.
+	if base == "" || !filepath.IsAbs(base) {
+
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+
+	return filepath.Join(base, "go-roguelike", "saves"), nil
+}
+
+// slotBaseName ... turn a character name into a safe file name.
+/*
+ * @param     string    name of the character
+ *
+ * @return    string    lowercase name holding only letters, digits and -
+ */
+func slotBaseName(name string) string {
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+
+	if b.Len() == 0 {
+		return "anonymous"
+	}
+
+	return b.String()
+}
+
+// newSlotName ... pick an unused slot for a character, so that two
+// characters of the same name never overwrite one another.
+/*
+ * @param     string    save directory
+ * @param     string    name of the character
+ *
+ * @return    string    name of the slot
+ */
+func newSlotName(dir string, name string) string {
+
+	base := slotBaseName(name)
+	slot := base
+
+	for i := 2; ; i++ {
+		_, err := os.Stat(filepath.Join(dir, slot+saveExtension))
+		if errors.Is(err, fs.ErrNotExist) {
+			return slot
+		}
+		slot = base + "-" + strconv.Itoa(i)
+	}
+}
+
+// SaveToSlot ... save the game to the slot of the current character,
+// picking a new slot on the first save.
+/*
+ * @return    error    error message, if any
+ */
+func (g *Game) SaveToSlot() error {
+
+	if g.Player == nil {
+		return fmt.Errorf("SaveToSlot() --> invalid input")
+	}
+
+	// A replay must never overwrite the saves of the player.
+	if ReplayFile != "" {
+		return nil
+	}
+
+	dir, err := SaveDir()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	if g.slot == "" {
+		g.slot = newSlotName(dir, g.Player.name)
+	}
+
+	return g.SaveGame(filepath.Join(dir, g.slot+saveExtension))
+}
+
+// LoadFromSlot ... load the game saved in the given slot.
+/*
+ * @param     SaveSlot    slot to load
+ *
+ * @return    error       error message, if any
+ */
+func (g *Game) LoadFromSlot(slot SaveSlot) error {
+
+	if err := g.LoadGame(slot.Path); err != nil {
+		return err
+	}
+
+	g.slot = slot.Name
+
+	// A game from the working directory moves into a slot of its own the
+	// next time it is saved.
+	if slot.Path == legacySaveFile {
+		g.slot = ""
+	}
+
+	return nil
+}
+
+// ListSaveSlots ... list every saved game, most recently saved first.
+/*
+ * @return    SaveSlot[]    saved games
+ *            error         error message, if any
+ */
+func ListSaveSlots() ([]SaveSlot, error) {
+
+	dir, err := SaveDir()
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+saveExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	// Games saved before there were save slots are listed as well, so
+	// that they are not lost.
+	if _, err := os.Stat(legacySaveFile); err == nil {
+		paths = append(paths, legacySaveFile)
+	}
+
+	slots := make([]SaveSlot, 0, len(paths))
+	for _, path := range paths {
+
+		slot := SaveSlot{Name: strings.TrimSuffix(filepath.Base(path),
+			saveExtension), Path: path}
+
+		summary, err := readSaveSummary(path)
+		if err != nil {
+			slot.Err = err
+		} else {
+			slot.Character, slot.Class = summary.Name, summary.Class
+			slot.Depth, slot.Turns = summary.Depth, summary.Turns
+			slot.SavedAt = summary.SavedAt
+		}
+
+		slots = append(slots, slot)
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].SavedAt.After(slots[j].SavedAt)
+	})
+
+	return slots, nil
+}
+
+// DeleteSaveSlot ... remove a saved game for good.
+/*
+ * @param     SaveSlot    slot to delete
+ *
+ * @return    error       error message, if any
+ */
+func DeleteSaveSlot(slot SaveSlot) error {
+	return os.Remove(slot.Path)
+}
+
+// Describe ... summary of the slot for the load screen, split over two
+// lines so that it fits on narrow terminals.
+/*
+ * @return    string[]    details of the saved game
+ */
+func (slot SaveSlot) Describe() []string {
+
+	if slot.Err != nil {
+		return []string{slot.Name, "   Unreadable: " + slot.Err.Error()}
+	}
+
+	return []string{
+		fmt.Sprintf("%s the %s", slot.Character, slot.Class),
+		fmt.Sprintf("   Depth %d | Turn %d | %s", slot.Depth, slot.Turns,
+			slot.SavedAt.Format("2006-01-02 15:04")),
+	}
+}
@@ -6,20 +6,76 @@
 
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/rbisewski/go_roguelike/types"
+)
+
+// sortedCreatureTypeNames ... names of every creature type, sorted.
+/*
+ * @return    string[]    sorted list of creature type names
+ */
+func sortedCreatureTypeNames() []string {
+
+	names := make([]string, 0, len(GlobalCreatureTypeInfoMap))
+	for k := range GlobalCreatureTypeInfoMap {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// spawnableCreatureTypeNames ... names of the creature types that may be
+// spawned at random, sorted; shopkeepers are only ever placed in shops.
+/*
+ * @return    string[]    sorted list of creature type names
+ */
+func spawnableCreatureTypeNames() []string {
+
+	names := make([]string, 0, len(GlobalCreatureTypeInfoMap))
+	for _, k := range sortedCreatureTypeNames() {
+		behaviour := GlobalCreatureTypeInfoMap[k].Behaviour
+		if behaviour != types.BehaviourShopkeeper {
+			names = append(names, k)
+		}
+	}
+
+	return names
+}
+
+// sortedItemTypeNames ... names of every item type, sorted.
+/*
+ * @return    string[]    sorted list of item type names
+ */
+func sortedItemTypeNames() []string {
+
+	names := make([]string, 0, len(GlobalItemTypeInfoMap))
+	for k := range GlobalItemTypeInfoMap {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	return names
+}
 
 //! Function to spawn a creature in a given area.
 /*
- * @param     string    name of the creature to add
- * @param     int       x-coord as int
- * @param     int       y-coord as int
- * @param     Area*     pointer to the intended area
+ * @param     string       name of the creature to add
+ * @param     int          x-coord as int
+ * @param     int          y-coord as int
+ * @param     Area*        pointer to the intended area
+ * @param     rand.Rand*   spawning random number stream
  *
- * @return    bool      whether or not the creature was added
+ * @return    bool         whether or not the creature was added
  */
-func spawnCreatureToArray(name string, x int, y int, a *Area) bool {
+func spawnCreatureToArray(name string, x int, y int, a *Area,
+	r *rand.Rand) bool {
 
-	if len(name) < 1 || x < 0 || y < 0 || a == nil {
+	if len(name) < 1 || x < 0 || y < 0 || a == nil || r == nil {
 		DebugLog(&G, fmt.Sprintf("spawnCreatureToArray() --> invalid input"))
 		return false
 	}
@@ -51,15 +107,148 @@ func spawnCreatureToArray(name string, x int, y int, a *Area) bool {
 	SpawnedCreatureWisdom := GlobalCreatureTypeInfoMap[name].Wisdom
 	SpawnedCreatureHealrate := GlobalCreatureTypeInfoMap[name].Healrate
 	SpawnedCreatureHealcounter := GlobalCreatureTypeInfoMap[name].Healcounter
+	SpawnedCreatureBehaviour := GlobalCreatureTypeInfoMap[name].Behaviour
+	SpawnedCreatureExperience := GlobalCreatureTypeInfoMap[name].Experience
 
-	// Append it to the array.
-	a.Creatures = append(a.Creatures, NewCreature(SpawnedCreatureName,
-		SpawnedCreatureSpecies, y, x, SpawnedCreatureGfx, a, nil,
+	// Roll how much gold the creature is carrying, if any.
+	SpawnedCreatureGold := 0
+	if maxGold := GlobalCreatureTypeInfoMap[name].Gold; maxGold > 0 {
+		SpawnedCreatureGold = r.Intn(maxGold + 1)
+	}
+
+	// Creatures found deeper in the dungeon are tougher; each level below
+	// the first adds 25% health, experience and gold, plus a point of
+	// attack and half a point of defence.
+	if a.Depth > 1 {
+		SpawnedCreatureExperience += SpawnedCreatureExperience * (a.Depth - 1) / 4
+		SpawnedCreatureGold += SpawnedCreatureGold * (a.Depth - 1) / 4
+		SpawnedCreatureHp += SpawnedCreatureHp * (a.Depth - 1) / 4
+		SpawnedCreatureMaxHp += SpawnedCreatureMaxHp * (a.Depth - 1) / 4
+		SpawnedCreatureAttack += a.Depth - 1
+		SpawnedCreatureDefence += (a.Depth - 1) / 2
+	}
+
+	// Roll for each item in the loot table of the creature.
+	SpawnedCreatureInventory := rollLoot(GlobalCreatureTypeInfoMap[name].Loot,
+		int(SpawnedCreatureStrength)*gramsPerStrength, r)
+
+	SpawnedCreature := NewCreature(SpawnedCreatureName,
+		SpawnedCreatureSpecies, y, x, SpawnedCreatureGfx, a,
+		SpawnedCreatureInventory,
 		SpawnedCreatureHp, SpawnedCreatureMaxHp, SpawnedCreatureAttack,
 		SpawnedCreatureDefence, SpawnedCreatureClass,
 		SpawnedCreatureStrength, SpawnedCreatureIntelligence,
 		SpawnedCreatureAgility, SpawnedCreatureWisdom, SpawnedCreatureHealrate,
-		SpawnedCreatureHealcounter))
+		SpawnedCreatureHealcounter, SpawnedCreatureBehaviour,
+		SpawnedCreatureExperience)
+	SpawnedCreature.Gold = SpawnedCreatureGold
+	SpawnedCreature.onHit = GlobalCreatureTypeInfoMap[name].OnHit
+	SpawnedCreature.speed = GlobalCreatureTypeInfoMap[name].Speed
+
+	// Append it to the array.
+	a.Creatures = append(a.Creatures, SpawnedCreature)
+
+	return true
+}
+
+// rollLoot ... decide which items from a loot table a creature is carrying;
+// a creature never carries more than it can without being slowed.
+/*
+ * @param     LootEntry[]   loot table of the creature type
+ * @param     int           carrying capacity of the creature, in grams
+ * @param     rand.Rand*    spawning random number stream
+ *
+ * @return    Item[]        items being carried, or nil if none
+ */
+func rollLoot(loot []types.LootEntry, capacity int, r *rand.Rand) []*Item {
+
+	var inventory []*Item
+	weight := 0
+
+	for _, entry := range loot {
+
+		if r.Intn(100) >= entry.Chance {
+			continue
+		}
+
+		// Items being carried are not on any level.
+		itm := NewItemFromType(entry.Item, 0, 0, nil)
+		if itm == nil || weight+itm.weight > capacity {
+			continue
+		}
+
+		weight += itm.weight
+		inventory = append(inventory, itm)
+	}
+
+	return inventory
+}
+
+// pickItemType ... choose an item type to place on a level, weighted by
+// rarity, out of those that may be found at the given depth.
+/*
+ * @param     int          depth of the level
+ * @param     rand.Rand*   spawning random number stream
+ *
+ * @return    string       name of the item type, or "" if none
+ */
+func pickItemType(depth int, r *rand.Rand) string {
+
+	// Go thru the item types in sorted order, since the order of a map
+	// is random and would make the seed meaningless.
+	names := make([]string, 0)
+	weights := make([]int, 0)
+	total := 0
+	for _, name := range sortedItemTypeNames() {
+
+		info := GlobalItemTypeInfoMap[name]
+		if info.Min_depth > depth {
+			continue
+		}
+
+		weight := types.RarityWeight(info.Rarity)
+		names = append(names, name)
+		weights = append(weights, weight)
+		total += weight
+	}
+
+	if total == 0 {
+		return ""
+	}
+
+	roll := r.Intn(total)
+	for i, weight := range weights {
+		if roll < weight {
+			return names[i]
+		}
+		roll -= weight
+	}
+
+	return ""
+}
+
+//! Function to spawn an item onto the ground of a given area.
+/*
+ * @param     string    name of the item type to add
+ * @param     int       x-coord as int
+ * @param     int       y-coord as int
+ * @param     Area*     pointer to the intended area
+ *
+ * @return    bool      whether or not the item was added
+ */
+func spawnItemToArray(name string, x int, y int, a *Area) bool {
+
+	if len(name) < 1 || x < 0 || y < 0 || a == nil {
+		DebugLog(&G, fmt.Sprintf("spawnItemToArray() --> invalid input"))
+		return false
+	}
+
+	itm := NewItemFromType(name, y, x, a)
+	if itm == nil {
+		return false
+	}
+
+	a.Items = append(a.Items, itm)
 
 	return true
 }
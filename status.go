@@ -0,0 +1,274 @@
+/*
+ * File: status.go
+ *
+ * Description: Status effects, such as poison or haste, which last a
+ *              creature for a number of turns and take their toll or give
+ *              their benefit on every one of them.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/rbisewski/go_roguelike/types"
+)
+
+// StatusEffect ... a status effect a creature is suffering from
+type StatusEffect struct {
+
+	// Name of the effect, e.g. types.StatusPoison.
+	Name string
+
+	// Number of turns left before it wears off.
+	Turns int
+
+	// Strength of the effect, e.g. hit points lost to poison each turn.
+	Amount int
+}
+
+// How a status effect combines with another of its kind that takes hold
+// while it is still in effect.
+const (
+	// The longer of the two lasts, at the greater of the two strengths.
+	stackRefresh = iota
+
+	// The strengths add up, and the longer of the two lasts.
+	stackIntensity
+
+	// The lengths add up, at the greater of the two strengths.
+	stackDuration
+)
+
+// statusRule ... how a status effect stacks, and what it is called
+type statusRule struct {
+
+	// Shown in the stats window, e.g. "Poison".
+	label string
+
+	// One of the stack constants.
+	stacking int
+
+	// Told to the player when it takes hold, and when it wears off.
+	onset  string
+	expiry string
+
+	// Effect it cancels out when it takes hold, if any.
+	cancels string
+}
+
+// statusRules ... every status effect, keyed by name
+var statusRules = map[string]statusRule{
+	types.StatusPoison: {"Poison", stackIntensity,
+		"You are poisoned!", "The poison wears off.", ""},
+	types.StatusBleeding: {"Bleed", stackDuration,
+		"You are bleeding!", "Your bleeding stops.", ""},
+	types.StatusStun: {"Stun", stackRefresh,
+		"You are stunned!", "You are no longer stunned.", ""},
+	types.StatusSlow: {"Slow", stackRefresh,
+		"You feel yourself slow down.", "You are no longer slowed.",
+		types.StatusHaste},
+	types.StatusHaste: {"Haste", stackRefresh,
+		"You feel yourself speed up.", "You feel yourself slow down.",
+		types.StatusSlow},
+	types.StatusRegeneration: {"Regen", stackRefresh,
+		"Your wounds begin to knit.", "Your wounds stop knitting.", ""},
+	types.StatusBlindness: {"Blind", stackRefresh,
+		"You are blinded!", "You can see again.", ""},
+	types.StatusEntangled: {"Webbed", stackRefresh,
+		"You are caught in webs!", "You break free of the webs.", ""},
+}
+
+// status ... the given status effect of the creature, if it is suffering
+// from it.
+/*
+ * @param     string           name of the effect
+ *
+ * @return    StatusEffect*    the effect, or nil if none
+ */
+func (m *Creature) status(name string) *StatusEffect {
+
+	if m == nil {
+		return nil
+	}
+
+	for i := range m.statuses {
+		if m.statuses[i].Name == name {
+			return &m.statuses[i]
+		}
+	}
+
+	return nil
+}
+
+// hasStatus ... whether the creature is suffering from the given effect.
+/*
+ * @param     string    name of the effect
+ *
+ * @return    bool      true if in effect
+ */
+func (m *Creature) hasStatus(name string) bool {
+	return m.status(name) != nil
+}
+
+// stunned ... whether the creature is stunned, and so cannot act; a
+// stunned creature simply loses the time of whatever it tried to do.
+/*
+ * @return    bool    true if stunned
+ */
+func (m *Creature) stunned() bool {
+
+	if !m.hasStatus(types.StatusStun) {
+		return false
+	}
+
+	m.notify("You are stunned, and cannot act.")
+	m.spend(actionRest)
+
+	return true
+}
+
+// addStatus ... make a status effect take hold of the creature, stacking
+// it with any of the same kind already in effect.
+/*
+ * @param     string    name of the effect
+ * @param     int       number of turns it lasts
+ * @param     int       strength of the effect
+ *
+ * @return    none
+ */
+func (m *Creature) addStatus(name string, turns, amount int) {
+
+	rule, exists := statusRules[name]
+	if m == nil || !exists || turns < 1 {
+		DebugLog(&G, fmt.Sprintf("addStatus() --> invalid status %q", name))
+		return
+	}
+
+	// Effects such as haste and slow cancel one another out.
+	if rule.cancels != "" && m.hasStatus(rule.cancels) {
+		m.removeStatus(rule.cancels)
+		return
+	}
+
+	m.notify(rule.onset)
+
+	current := m.status(name)
+	if current == nil {
+		m.statuses = append(m.statuses, StatusEffect{name, turns, amount})
+		return
+	}
+
+	switch rule.stacking {
+	case stackIntensity:
+		current.Turns = Max(current.Turns, turns)
+		current.Amount += amount
+	case stackDuration:
+		current.Turns += turns
+		current.Amount = Max(current.Amount, amount)
+	default:
+		current.Turns = Max(current.Turns, turns)
+		current.Amount = Max(current.Amount, amount)
+	}
+}
+
+// removeStatus ... end a status effect, telling the player it wore off.
+/*
+ * @param     string    name of the effect
+ *
+ * @return    none
+ */
+func (m *Creature) removeStatus(name string) {
+
+	for i, effect := range m.statuses {
+		if effect.Name == name {
+			m.statuses = append(m.statuses[:i], m.statuses[i+1:]...)
+			m.notify(statusRules[name].expiry)
+			return
+		}
+	}
+}
+
+// inflictOnHit ... roll for each status effect the attacks of the creature
+// may inflict whether it takes hold of the creature that was hit.
+/*
+ * @param     Creature*    creature that was hit
+ * @param     rand.Rand*   combat random number stream
+ *
+ * @return    none
+ */
+func (m *Creature) inflictOnHit(defender *Creature, r *rand.Rand) {
+
+	for _, effect := range m.onHit {
+		if defender.Hp > 0 && r.Intn(100) < effect.Chance {
+			defender.addStatus(effect.Status, effect.Turns, effect.Amount)
+		}
+	}
+}
+
+// tickStatuses ... apply every status effect of the creature for another
+// turn, and end those that have worn off.
+/*
+ * @return    none
+ */
+func (m *Creature) tickStatuses() {
+
+	for _, effect := range append([]StatusEffect(nil), m.statuses...) {
+
+		switch effect.Name {
+		case types.StatusPoison:
+			m.notify(fmt.Sprintf("The poison saps %d hit points.",
+				effect.Amount))
+			m.Hp -= effect.Amount
+		case types.StatusBleeding:
+			m.notify(fmt.Sprintf("You bleed for %d hit points.",
+				effect.Amount))
+			m.Hp -= effect.Amount
+		case types.StatusRegeneration:
+			restoreHp(m, effect.Amount)
+		}
+
+		if m.Hp <= 0 {
+			m.die()
+			return
+		}
+
+		if current := m.status(effect.Name); current != nil {
+			current.Turns--
+			if current.Turns < 1 {
+				m.removeStatus(effect.Name)
+			}
+		}
+	}
+}
+
+// sightRadius ... how many tiles away the creature can see.
+/*
+ * @return    int    sight radius
+ */
+func (m *Creature) sightRadius() int {
+
+	if m.hasStatus(types.StatusBlindness) {
+		return 1
+	}
+
+	return SightRadius
+}
+
+// statusSummary ... every status effect of the creature along with the
+// turns it has left, as shown in the stats window, e.g. "Poison 3 Slow 2".
+/*
+ * @return    string    summary of the status effects
+ */
+func (m *Creature) statusSummary() string {
+
+	labels := make([]string, 0, len(m.statuses))
+	for _, effect := range m.statuses {
+		labels = append(labels, fmt.Sprintf("%s %d",
+			statusRules[effect.Name].label, effect.Turns))
+	}
+
+	return strings.Join(labels, " ")
+}
@@ -6,6 +6,11 @@
 
 package types
 
+import (
+	"fmt"
+	"strconv"
+)
+
 // Structure to hold creature information
 type ClassTypeInfo struct {
 
@@ -33,42 +38,103 @@ type ClassTypeInfo struct {
 	EssentialAttribute string
 }
 
-//! Function to populate details about various class types
+// classDefinition ... JSON form of a class type
+type classDefinition struct {
+	Name               string `json:"name"`
+	HasAbilities       string `json:"abilities"`
+	EssentialAttribute string `json:"essential_attribute"`
+}
+
+// LoadClassTypes ... populate details about various class types, from
+// the embedded defaults plus dataDir/classes.json (if present).
 /*
- * @return    none
+ * @param     map      class types, keyed by the number shown in the menu
+ * @param     string   data directory, or "" for the defaults only
+ *
+ * @return    error    error message, if any
  */
-func GenClassTypes(clstype map[string]ClassTypeInfo) bool {
+func LoadClassTypes(clstype map[string]ClassTypeInfo, dataDir string) error {
 
 	// Input validation
 	if clstype == nil {
-		return false
+		return fmt.Errorf("LoadClassTypes() --> invalid input")
 	}
 
-	//
-	// Unknown
-	//
-	clstype["0"] = ClassTypeInfo{"Unknown", "unknown", "unknown"}
+	files, err := readDefinitionFiles("classes.json", dataDir)
+	if err != nil {
+		return err
+	}
 
-	//
-	// Warrior
-	//
-	clstype["1"] = ClassTypeInfo{"Warrior", "warrior", "strength"}
+	// Layer the definitions from each file on top of one another.
+	defs := make(map[string]*classDefinition)
+	source := make(map[string]string)
+	for _, file := range files {
+		for key, raw := range file.entries {
 
-	//
-	// Wizard
-	//
-	clstype["2"] = ClassTypeInfo{"Wizard", "wizard", "intelligence"}
+			if defs[key] == nil {
+				defs[key] = &classDefinition{}
+			}
 
-	//
-	// Thief
-	//
-	clstype["3"] = ClassTypeInfo{"Thief", "thief", "agility"}
+			if err := decodeEntry(file.name, key, raw, defs[key]); err != nil {
+				return err
+			}
+			source[key] = file.name
+		}
+	}
 
-	//
-	// Cleric
-	//
-	clstype["4"] = ClassTypeInfo{"Cleric", "cleric", "wisdom"}
+	// Validate every definition before touching the map.
+	loaded := make(map[string]ClassTypeInfo)
+	for _, key := range sortedKeys(source) {
+
+		// The menu lists the classes by number, so the keys must be too.
+		if _, err := strconv.Atoi(key); err != nil {
+			return &LoadError{File: source[key], Entry: key,
+				Msg: "class keys must be numbers"}
+		}
+
+		info, loadErr := defs[key].toInfo()
+		if loadErr != nil {
+			loadErr.File = source[key]
+			loadErr.Entry = key
+			return loadErr
+		}
+
+		loaded[key] = info
+	}
+
+	for key, info := range loaded {
+		clstype[key] = info
+	}
+
+	return nil
+}
+
+// toInfo ... validate a class definition and convert it.
+/*
+ * @return    ClassTypeInfo    the converted class type
+ *            LoadError*       error, if any
+ */
+func (d *classDefinition) toInfo() (ClassTypeInfo, *LoadError) {
+
+	var info ClassTypeInfo
+
+	if d.Name == "" {
+		return info, fieldError("name", "is required")
+	}
+
+	switch d.HasAbilities {
+	case "warrior", "thief", "cleric", "wizard", "unknown":
+	default:
+		return info, fieldError("abilities", "unknown abilities %q",
+			d.HasAbilities)
+	}
+
+	switch d.EssentialAttribute {
+	case "strength", "intelligence", "agility", "wisdom", "unknown":
+	default:
+		return info, fieldError("essential_attribute",
+			"unknown attribute %q", d.EssentialAttribute)
+	}
 
-	// All of the classes have been populated successfully, so return true.
-	return true
+	return ClassTypeInfo{d.Name, d.HasAbilities, d.EssentialAttribute}, nil
 }
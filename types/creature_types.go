@@ -6,6 +6,100 @@
 
 package types
 
+import "fmt"
+
+// Behaviour profiles of a creature type, which decide how the monster AI
+// acts on its turn.
+const (
+	// Chases the player when nearby; the default.
+	BehaviourAggressive = "aggressive"
+
+	// Chases the player, but runs away when badly hurt.
+	BehaviourCowardly = "cowardly"
+
+	// Guards the area around where it was spawned.
+	BehaviourTerritorial = "territorial"
+
+	// Roams at random, only fighting when the player is adjacent.
+	BehaviourWanderer = "wanderer"
+
+	// Hunts from further away when others of its species are nearby.
+	BehaviourPackHunter = "pack_hunter"
+
+	// Waits in place until the player is adjacent.
+	BehaviourAmbusher = "ambusher"
+
+	// Minds a shop and never moves, until wronged by the player; only
+	// ever placed in a shop, never spawned at random.
+	BehaviourShopkeeper = "shopkeeper"
+)
+
+// behaviours ... every valid behaviour profile
+var behaviours = []string{BehaviourAggressive, BehaviourCowardly,
+	BehaviourTerritorial, BehaviourWanderer, BehaviourPackHunter,
+	BehaviourAmbusher, BehaviourShopkeeper}
+
+// Status effects that a creature can suffer from for a number of turns.
+const (
+	// Loses the given number of hit points every turn; stacks in
+	// strength.
+	StatusPoison = "poison"
+
+	// Loses the given number of hit points every turn; stacks in length.
+	StatusBleeding = "bleeding"
+
+	// Cannot act at all.
+	StatusStun = "stun"
+
+	// Acts at half its usual speed.
+	StatusSlow = "slow"
+
+	// Acts at half as fast again as its usual speed.
+	StatusHaste = "haste"
+
+	// Recovers the given number of hit points every turn.
+	StatusRegeneration = "regeneration"
+
+	// Cannot see beyond the tiles next to it.
+	StatusBlindness = "blindness"
+
+	// Caught in webs, and cannot move, though it can still fight.
+	StatusEntangled = "entangled"
+)
+
+// statuses ... every valid status effect
+var statuses = []string{StatusPoison, StatusBleeding, StatusStun,
+	StatusSlow, StatusHaste, StatusRegeneration, StatusBlindness,
+	StatusEntangled}
+
+// HitEffect ... a status effect that the attacks of a creature may
+// inflict on whatever they hit
+type HitEffect struct {
+
+	// Status effect inflicted, e.g. StatusPoison.
+	Status string `json:"status"`
+
+	// Percentage chance of a hit inflicting it.
+	Chance int `json:"chance"`
+
+	// Number of turns it lasts.
+	Turns int `json:"turns"`
+
+	// Strength of the effect, e.g. hit points lost to poison each turn.
+	Amount int `json:"amount"`
+}
+
+// LootEntry ... an item that a creature may be carrying when it spawns,
+// and drops when it dies
+type LootEntry struct {
+
+	// Key of the item type, e.g. "dagger".
+	Item string `json:"item"`
+
+	// Percentage chance of the creature carrying the item.
+	Chance int `json:"chance"`
+}
+
 // Structure to hold creature information
 type CreatureTypeInfo struct {
 
@@ -38,53 +132,250 @@ type CreatureTypeInfo struct {
 
 	// The number of steps currently walked by the creature in question.
 	Healcounter uint
+
+	// How the monster AI treats the creature, e.g. BehaviourAmbusher.
+	Behaviour string
+
+	// Experience awarded to the player for killing the creature.
+	Experience int
+
+	// Items the creature may be carrying.
+	Loot []LootEntry
+
+	// Most gold the creature may be carrying.
+	Gold int
+
+	// Status effects its attacks may inflict.
+	OnHit []HitEffect
+
+	// How quickly the creature acts; 100 is the pace of the player.
+	Speed int
+}
+
+// creatureDefinition ... JSON form of a creature type
+type creatureDefinition struct {
+	Name         string      `json:"name"`
+	Species      string      `json:"species"`
+	Ch           string      `json:"ch"`
+	Hp           int         `json:"hp"`
+	MaxHp        int         `json:"max_hp"`
+	Att          int         `json:"att"`
+	Def          int         `json:"def"`
+	Class        string      `json:"class"`
+	Strength     uint        `json:"strength"`
+	Intelligence uint        `json:"intelligence"`
+	Agility      uint        `json:"agility"`
+	Wisdom       uint        `json:"wisdom"`
+	Healrate     uint        `json:"healrate"`
+	Healcounter  uint        `json:"healcounter"`
+	Behaviour    string      `json:"behaviour"`
+	Experience   int         `json:"experience"`
+	Loot         []LootEntry `json:"loot"`
+	Gold         int         `json:"gold"`
+	OnHit        []HitEffect `json:"on_hit"`
+	Speed        int         `json:"speed"`
 }
 
-//! Function to populate details about various creature types
+// LoadCreatureTypes ... populate details about various creature types,
+// from the embedded defaults plus dataDir/creatures.json (if present).
 /*
- * @return    none
+ * @param     map      creature types, keyed by name
+ * @param     map      class types, used to look up the "class" field
+ * @param     map      item types, used to look up the "loot" field
+ * @param     string   data directory, or "" for the defaults only
+ *
+ * @return    error    error message, if any
  */
-func GenCreatureTypes(ct map[string]CreatureTypeInfo) bool {
+func LoadCreatureTypes(ct map[string]CreatureTypeInfo,
+	classes map[string]ClassTypeInfo, items map[string]ItemTypeInfo,
+	dataDir string) error {
 
 	if ct == nil {
-		return false
-	}
-
-	//
-	// Dog
-	//
-	ct["dog"] = CreatureTypeInfo{"dog", "canine", 'd', 20, 20, 5, 0, nil,
-		20, 10, 10, 10, 10, 0}
-
-	//
-	// Wolf
-	//
-	ct["wolf"] = CreatureTypeInfo{"wolf", "canine", 'w', 25, 25, 7, 0, nil,
-		20, 10, 10, 10, 10, 0}
-
-	//
-	// Snake
-	//
-	ct["snake"] = CreatureTypeInfo{"snake", "reptile", 's', 18, 18, 10, 1,
-		nil, 20, 10, 10, 10, 10, 0}
-
-	//
-	// Snake
-	//
-	ct["spider"] = CreatureTypeInfo{"spider", "arthropod", 'x', 8, 8, 2, 2,
-		nil, 20, 10, 10, 10, 10, 0}
-
-	//
-	// Goblin
-	//
-	ct["goblin"] = CreatureTypeInfo{"goblin", "humanoid", 'g', 22, 22, 4,
-		2, nil, 20, 10, 10, 10, 10, 0}
-
-	//
-	// Orc
-	//
-	ct["orc"] = CreatureTypeInfo{"orc", "humanoid", 'o', 40, 40, 12, 5, nil,
-		20, 10, 10, 10, 10, 0}
-
-	return true
+		return fmt.Errorf("LoadCreatureTypes() --> invalid input")
+	}
+
+	files, err := readDefinitionFiles("creatures.json", dataDir)
+	if err != nil {
+		return err
+	}
+
+	// Layer the definitions from each file on top of one another.
+	defs := make(map[string]*creatureDefinition)
+	source := make(map[string]string)
+	for _, file := range files {
+		for key, raw := range file.entries {
+
+			if defs[key] == nil {
+				defs[key] = &creatureDefinition{}
+			}
+
+			if err := decodeEntry(file.name, key, raw, defs[key]); err != nil {
+				return err
+			}
+			source[key] = file.name
+		}
+	}
+
+	// Validate every definition before touching the map.
+	loaded := make(map[string]CreatureTypeInfo)
+	for _, key := range sortedKeys(source) {
+
+		info, loadErr := defs[key].toInfo(classes, items)
+		if loadErr != nil {
+			loadErr.File = source[key]
+			loadErr.Entry = key
+			return loadErr
+		}
+
+		loaded[key] = info
+	}
+
+	for key, info := range loaded {
+		ct[key] = info
+	}
+
+	return nil
+}
+
+// toInfo ... validate a creature definition and convert it.
+/*
+ * @param     map                 class types, keyed by number
+ * @param     map                 item types, keyed by name
+ *
+ * @return    CreatureTypeInfo    the converted creature type
+ *            LoadError*          error, if any
+ */
+func (d *creatureDefinition) toInfo(classes map[string]ClassTypeInfo,
+	items map[string]ItemTypeInfo) (CreatureTypeInfo, *LoadError) {
+
+	var info CreatureTypeInfo
+
+	if d.Name == "" {
+		return info, fieldError("name", "is required")
+	}
+	if d.Species == "" {
+		return info, fieldError("species", "is required")
+	}
+
+	ch, err := parseRune("ch", d.Ch)
+	if err != nil {
+		return info, err
+	}
+
+	if d.MaxHp < 1 {
+		return info, fieldError("max_hp", "must be greater than zero")
+	}
+	if d.Hp < 1 || d.Hp > d.MaxHp {
+		return info, fieldError("hp", "must be between 1 and max_hp (%d)",
+			d.MaxHp)
+	}
+	if d.Att < 0 {
+		return info, fieldError("att", "must not be negative")
+	}
+	if d.Def < 0 {
+		return info, fieldError("def", "must not be negative")
+	}
+	if d.Healrate < 1 {
+		return info, fieldError("healrate", "must be greater than zero")
+	}
+	if d.Experience < 0 {
+		return info, fieldError("experience", "must not be negative")
+	}
+	if d.Gold < 0 {
+		return info, fieldError("gold", "must not be negative")
+	}
+
+	// Creatures move at the pace of the player, unless told otherwise.
+	speed := d.Speed
+	if speed == 0 {
+		speed = 100
+	}
+	if speed < 0 {
+		return info, fieldError("speed", "must be greater than zero")
+	}
+
+	// Creatures without a profile simply chase the player.
+	behaviour := d.Behaviour
+	if behaviour == "" {
+		behaviour = BehaviourAggressive
+	}
+	if !isBehaviour(behaviour) {
+		return info, fieldError("behaviour", "unknown behaviour %q, "+
+			"expected one of %v", behaviour, behaviours)
+	}
+
+	// Creatures may optionally belong to one of the classes.
+	var class *ClassTypeInfo
+	if d.Class != "" {
+		c, exists := classes[d.Class]
+		if !exists {
+			return info, fieldError("class", "unknown class %q", d.Class)
+		}
+		class = &c
+	}
+
+	// Every item in the loot table must be a known item type.
+	for _, entry := range d.Loot {
+		if _, exists := items[entry.Item]; !exists {
+			return info, fieldError("loot", "unknown item %q", entry.Item)
+		}
+		if entry.Chance < 1 || entry.Chance > 100 {
+			return info, fieldError("loot", "chance of %q must be between "+
+				"1 and 100", entry.Item)
+		}
+	}
+
+	// Every status effect inflicted must be a known one.
+	for _, effect := range d.OnHit {
+		if !IsStatus(effect.Status) {
+			return info, fieldError("on_hit", "unknown status %q, expected "+
+				"one of %v", effect.Status, statuses)
+		}
+		if effect.Chance < 1 || effect.Chance > 100 {
+			return info, fieldError("on_hit", "chance of %q must be between "+
+				"1 and 100", effect.Status)
+		}
+		if effect.Turns < 1 {
+			return info, fieldError("on_hit", "turns of %q must be greater "+
+				"than zero", effect.Status)
+		}
+		if effect.Amount < 0 {
+			return info, fieldError("on_hit", "amount of %q must not be "+
+				"negative", effect.Status)
+		}
+	}
+
+	return CreatureTypeInfo{d.Name, d.Species, ch, d.Hp, d.MaxHp, d.Att,
+		d.Def, class, d.Strength, d.Intelligence, d.Agility, d.Wisdom,
+		d.Healrate, d.Healcounter, behaviour, d.Experience, d.Loot,
+		d.Gold, d.OnHit, speed}, nil
+}
+
+// isBehaviour ... whether the given string is a valid behaviour profile.
+func isBehaviour(s string) bool {
+
+	for _, b := range behaviours {
+		if s == b {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsStatus ... whether the given string is a valid status effect.
+/*
+ * @param     string    name of the status effect
+ *
+ * @return    bool      true if valid
+ */
+func IsStatus(s string) bool {
+
+	for _, status := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
 }
@@ -6,6 +6,65 @@
 
 package types
 
+import "fmt"
+
+// Effects that a consumable item can have when used.
+const (
+	// Restores the given number of hit points.
+	EffectHeal = "heal"
+
+	// Restores the given amount of mana.
+	EffectRestoreMana = "restore_mana"
+
+	// Moves the user to a random spot on the level.
+	EffectTeleport = "teleport"
+
+	// Reveals the layout of the level.
+	EffectMagicMapping = "magic_mapping"
+
+	// Restores the given durability to the most worn item carried.
+	EffectRepair = "repair"
+)
+
+// effects ... every valid item effect
+var effects = []string{EffectHeal, EffectRestoreMana, EffectTeleport,
+	EffectMagicMapping, EffectRepair}
+
+// How often an item type turns up in the dungeon.
+const (
+	RarityCommon   = "common"
+	RarityUncommon = "uncommon"
+	RarityRare     = "rare"
+	RarityVeryRare = "very_rare"
+)
+
+// rarityWeights ... relative chance of an item of each rarity being
+// picked, when placing items on a level
+var rarityWeights = map[string]int{
+	RarityCommon:   100,
+	RarityUncommon: 40,
+	RarityRare:     15,
+	RarityVeryRare: 5,
+}
+
+// RarityWeight ... relative chance of an item of the given rarity being
+// picked, compared to the other items that may be placed.
+/*
+ * @param     string    rarity, e.g. RarityCommon
+ *
+ * @return    int       weight, or 0 if the rarity is unknown
+ */
+func RarityWeight(rarity string) int {
+	return rarityWeights[rarity]
+}
+
+// ItemEffect ... a single effect of using an item, e.g. healing 10 hit
+// points
+type ItemEffect struct {
+	Type   string `json:"type"`
+	Amount int    `json:"amount"`
+}
+
 // Structure to hold creature information
 type ItemTypeInfo struct {
 
@@ -40,65 +99,191 @@ type ItemTypeInfo struct {
 	// or decreases (if negative) the attack / defence of a creature.
 	Attack_increase  int
 	Defence_increase int
+
+	// What happens when the item is used up, e.g. quaffing a potion; items
+	// with no effects cannot be used.
+	Effects []ItemEffect
+
+	// Verb shown when the item is used, e.g. "quaff" or "read".
+	Use_verb string
+
+	// How often the item is found lying in the dungeon, e.g. RarityRare,
+	// and the shallowest level it can be found on.
+	Rarity    string
+	Min_depth int
 }
 
-//! Function to populate details about various items types
+// itemDefinition ... JSON form of an item type
+type itemDefinition struct {
+	Name               string       `json:"name"`
+	Category           string       `json:"category"`
+	Ch                 string       `json:"ch"`
+	Can_equip          bool         `json:"can_equip"`
+	Is_broken          bool         `json:"is_broken"`
+	Durability_current int          `json:"durability_current"`
+	Durability_maximum int          `json:"durability_maximum"`
+	Price_to_purchase  int          `json:"price_to_purchase"`
+	Price_to_sell      int          `json:"price_to_sell"`
+	Weight             int          `json:"weight"`
+	Attack_increase    int          `json:"attack_increase"`
+	Defence_increase   int          `json:"defence_increase"`
+	Effects            []ItemEffect `json:"effects"`
+	Use_verb           string       `json:"use_verb"`
+	Rarity             string       `json:"rarity"`
+	Min_depth          int          `json:"min_depth"`
+}
+
+// LoadItemTypes ... populate details about various item types, from the
+// embedded defaults plus dataDir/items.json (if present).
 /*
- * @return    none
+ * @param     map      item types, keyed by name
+ * @param     string   data directory, or "" for the defaults only
+ *
+ * @return    error    error message, if any
  */
-func GenItemTypes(itype map[string]ItemTypeInfo) bool {
+func LoadItemTypes(itype map[string]ItemTypeInfo, dataDir string) error {
 
 	if itype == nil {
-		return false
-	}
-
-	//
-	// Dagger
-	//
-	itype["dagger"] = ItemTypeInfo{"Dagger", "blade", '%', true, false, 5,
-		5, 10, 5, 10000, 1, 0}
-
-	//
-	// Sword
-	//
-	itype["sword"] = ItemTypeInfo{"Sword", "blade", '%', true, false, 10,
-		10, 10, 5, 10000, 2, 0}
-
-	//
-	// Mace
-	//
-	itype["mace"] = ItemTypeInfo{"Mace", "blunt", '%', true, false, 8,
-		8, 11, 3, 8000, 2, 0}
-
-	//
-	// Buckler
-	//
-	itype["Buckler"] = ItemTypeInfo{"Buckler", "shield", '%', true, false, 11,
-		11, 20, 10, 20000, 0, 1}
-
-	//
-	// Helm
-	//
-	itype["Helm"] = ItemTypeInfo{"Helm", "helmet", '%', true, false, 10,
-		10, 25, 8, 15000, 0, 1}
-
-	//
-	// Amulet of Defence
-	//
-	itype["amulet_of_defence"] = ItemTypeInfo{"Amulet of Defence", "necklace",
-		'%', true, false, 20, 20, 50, 25, 5000, 0, 1}
-
-	//
-	// Leather Armour
-	//
-	itype["leather_armour"] = ItemTypeInfo{"Leather Armour", "armour", '%',
-		true, false, 15, 15, 40, 20, 75000, 0, 1}
-
-	//
-	// Greaves
-	//
-	itype["greaves"] = ItemTypeInfo{"Greaves", "pants", '%', true, false,
-		15, 15, 20, 10, 20000, 0, 2}
-
-	return true
+		return fmt.Errorf("LoadItemTypes() --> invalid input")
+	}
+
+	files, err := readDefinitionFiles("items.json", dataDir)
+	if err != nil {
+		return err
+	}
+
+	// Layer the definitions from each file on top of one another.
+	defs := make(map[string]*itemDefinition)
+	source := make(map[string]string)
+	for _, file := range files {
+		for key, raw := range file.entries {
+
+			if defs[key] == nil {
+				defs[key] = &itemDefinition{}
+			}
+
+			if err := decodeEntry(file.name, key, raw, defs[key]); err != nil {
+				return err
+			}
+			source[key] = file.name
+		}
+	}
+
+	// Validate every definition before touching the map.
+	loaded := make(map[string]ItemTypeInfo)
+	for _, key := range sortedKeys(source) {
+
+		info, loadErr := defs[key].toInfo()
+		if loadErr != nil {
+			loadErr.File = source[key]
+			loadErr.Entry = key
+			return loadErr
+		}
+
+		loaded[key] = info
+	}
+
+	for key, info := range loaded {
+		itype[key] = info
+	}
+
+	return nil
+}
+
+// toInfo ... validate an item definition and convert it.
+/*
+ * @return    ItemTypeInfo    the converted item type
+ *            LoadError*      error, if any
+ */
+func (d *itemDefinition) toInfo() (ItemTypeInfo, *LoadError) {
+
+	var info ItemTypeInfo
+
+	if d.Name == "" {
+		return info, fieldError("name", "is required")
+	}
+	if d.Category == "" {
+		return info, fieldError("category", "is required")
+	}
+
+	ch, err := parseRune("ch", d.Ch)
+	if err != nil {
+		return info, err
+	}
+
+	if d.Durability_maximum < 0 {
+		return info, fieldError("durability_maximum", "must not be negative")
+	}
+	if d.Durability_current < 0 || d.Durability_current > d.Durability_maximum {
+		return info, fieldError("durability_current",
+			"must be between 0 and durability_maximum (%d)",
+			d.Durability_maximum)
+	}
+	if d.Price_to_purchase < 0 {
+		return info, fieldError("price_to_purchase", "must not be negative")
+	}
+	if d.Price_to_sell < 0 {
+		return info, fieldError("price_to_sell", "must not be negative")
+	}
+	if d.Weight < 0 {
+		return info, fieldError("weight", "must not be negative")
+	}
+
+	for _, effect := range d.Effects {
+		if !isEffect(effect.Type) {
+			return info, fieldError("effects", "unknown effect %q, expected "+
+				"one of %v", effect.Type, effects)
+		}
+		if effect.Amount < 0 {
+			return info, fieldError("effects", "amount of %q must not be "+
+				"negative", effect.Type)
+		}
+	}
+
+	// Items are used, unless told otherwise.
+	verb := d.Use_verb
+	if verb == "" {
+		verb = "use"
+	}
+
+	// Items are common and found on any level, unless told otherwise.
+	rarity := d.Rarity
+	if rarity == "" {
+		rarity = RarityCommon
+	}
+	if RarityWeight(rarity) == 0 {
+		return info, fieldError("rarity", "unknown rarity %q, expected one "+
+			"of %v", rarity, []string{RarityCommon, RarityUncommon,
+			RarityRare, RarityVeryRare})
+	}
+
+	minDepth := d.Min_depth
+	if minDepth == 0 {
+		minDepth = 1
+	}
+	if minDepth < 1 {
+		return info, fieldError("min_depth", "must be greater than zero")
+	}
+
+	return ItemTypeInfo{d.Name, d.Category, ch, d.Can_equip, d.Is_broken,
+		d.Durability_current, d.Durability_maximum, d.Price_to_purchase,
+		d.Price_to_sell, d.Weight, d.Attack_increase,
+		d.Defence_increase, d.Effects, verb, rarity, minDepth}, nil
+}
+
+// isEffect ... whether the given name is a valid item effect.
+/*
+ * @param     string    name of the effect
+ *
+ * @return    bool      true if valid
+ */
+func isEffect(name string) bool {
+
+	for _, e := range effects {
+		if e == name {
+			return true
+		}
+	}
+
+	return false
 }
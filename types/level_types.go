@@ -0,0 +1,138 @@
+/*
+ * File: types/level_types.go
+ *
+ * Description: Hold type information about the levels of the dungeon.
+ */
+
+package types
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Map generators that can lay out a level.
+const (
+	// Caverns grown by cellular automata from randomly placed walls.
+	GeneratorCave = "cave"
+
+	// Rooms scattered at random, joined by corridors.
+	GeneratorRooms = "rooms"
+
+	// Rooms placed by recursively splitting the level in two, joined by
+	// corridors between the halves.
+	GeneratorBSP = "bsp"
+)
+
+// generators ... every valid map generator
+var generators = []string{GeneratorCave, GeneratorRooms, GeneratorBSP}
+
+// LevelTypeInfo ... Structure to hold level information
+type LevelTypeInfo struct {
+
+	// Map generator that lays out the level, e.g. GeneratorCave.
+	Generator string
+}
+
+// levelDefinition ... JSON form of a level type
+type levelDefinition struct {
+	Generator string `json:"generator"`
+}
+
+// LoadLevelTypes ... populate details about the levels of the dungeon,
+// from the embedded defaults plus dataDir/levels.json (if present).
+/*
+ * @param     map      level types, keyed by depth
+ * @param     string   data directory, or "" for the defaults only
+ *
+ * @return    error    error message, if any
+ */
+func LoadLevelTypes(lvltype map[string]LevelTypeInfo, dataDir string) error {
+
+	// Input validation
+	if lvltype == nil {
+		return fmt.Errorf("LoadLevelTypes() --> invalid input")
+	}
+
+	files, err := readDefinitionFiles("levels.json", dataDir)
+	if err != nil {
+		return err
+	}
+
+	// Layer the definitions from each file on top of one another.
+	defs := make(map[string]*levelDefinition)
+	source := make(map[string]string)
+	for _, file := range files {
+		for key, raw := range file.entries {
+
+			if defs[key] == nil {
+				defs[key] = &levelDefinition{}
+			}
+
+			if err := decodeEntry(file.name, key, raw, defs[key]); err != nil {
+				return err
+			}
+			source[key] = file.name
+		}
+	}
+
+	// Validate every definition before touching the map.
+	loaded := make(map[string]LevelTypeInfo)
+	for _, key := range sortedKeys(source) {
+
+		// Levels are looked up by depth, so the keys must be depths.
+		if depth, err := strconv.Atoi(key); err != nil || depth < 1 {
+			return &LoadError{File: source[key], Entry: key,
+				Msg: "level keys must be depths, starting at 1"}
+		}
+
+		info, loadErr := defs[key].toInfo()
+		if loadErr != nil {
+			loadErr.File = source[key]
+			loadErr.Entry = key
+			return loadErr
+		}
+
+		loaded[key] = info
+	}
+
+	for key, info := range loaded {
+		lvltype[key] = info
+	}
+
+	return nil
+}
+
+// toInfo ... validate a level definition and convert it.
+/*
+ * @return    LevelTypeInfo    the converted level type
+ *            LoadError*       error, if any
+ */
+func (d *levelDefinition) toInfo() (LevelTypeInfo, *LoadError) {
+
+	var info LevelTypeInfo
+
+	// Levels are caverns, unless told otherwise.
+	generator := d.Generator
+	if generator == "" {
+		generator = GeneratorCave
+	}
+	if !isGenerator(generator) {
+		return info, fieldError("generator", "unknown generator %q",
+			d.Generator)
+	}
+
+	return LevelTypeInfo{generator}, nil
+}
+
+// isGenerator ... whether the given string is a valid map generator.
+func isGenerator(s string) bool {
+
+	for _, g := range generators {
+		if s == g {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,227 @@
+/*
+ * File: types/loader.go
+ *
+ * Description: Shared routines for loading type definitions from JSON
+ *              files, both the embedded defaults and those found in an
+ *              optional data directory.
+ */
+
+package types
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Default set of creature, item, class and level definitions.
+//
+//go:embed data/*.json
+var defaultData embed.FS
+
+// LoadError ... describes a problem found in a type definition file
+type LoadError struct {
+
+	// File the problem was found in.
+	File string
+
+	// Line of the file, for syntax errors; zero if unknown.
+	Line int
+
+	// Key of the definition the problem was found in, if any.
+	Entry string
+
+	// Name of the offending field, if any.
+	Field string
+
+	// Description of the problem.
+	Msg string
+}
+
+// Error ... format the load error as "file:line: entry: field: message"
+func (e *LoadError) Error() string {
+
+	msg := e.File
+	if e.Line > 0 {
+		msg += fmt.Sprintf(":%d", e.Line)
+	}
+	if e.Entry != "" {
+		msg += fmt.Sprintf(": entry %q", e.Entry)
+	}
+	if e.Field != "" {
+		msg += fmt.Sprintf(": field %q", e.Field)
+	}
+
+	return msg + ": " + e.Msg
+}
+
+// fieldError ... start a load error about a given field; the file and
+// entry are filled in by the caller.
+func fieldError(field string, format string, a ...interface{}) *LoadError {
+	return &LoadError{Field: field, Msg: fmt.Sprintf(format, a...)}
+}
+
+// definitionFile ... the raw entries of a single definition file
+type definitionFile struct {
+	name    string
+	entries map[string]json.RawMessage
+}
+
+// readDefinitionFiles ... read the embedded defaults for the given file
+// name, followed by the file of the same name in dataDir (if any), so
+// that the latter can add to or override the former.
+/*
+ * @param     string              file name, e.g. "creatures.json"
+ * @param     string              data directory, or "" for defaults only
+ *
+ * @return    definitionFile[]    files in the order they should apply
+ *            error               error message, if any
+ */
+func readDefinitionFiles(name string, dataDir string) ([]definitionFile, error) {
+
+	files := make([]definitionFile, 0, 2)
+
+	data, err := defaultData.ReadFile("data/" + name)
+	if err != nil {
+		return nil, &LoadError{File: "data/" + name, Msg: err.Error()}
+	}
+
+	file, err := parseDefinitionFile("data/"+name, data)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, file)
+
+	if dataDir == "" {
+		return files, nil
+	}
+
+	// A data directory need not override every kind of definition, so
+	// a missing file is fine.
+	path := filepath.Join(dataDir, name)
+	data, err = os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, &LoadError{File: path, Msg: err.Error()}
+	}
+
+	file, err = parseDefinitionFile(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(files, file), nil
+}
+
+// parseDefinitionFile ... split a JSON object into its raw entries.
+/*
+ * @param     string            file name, for error messages
+ * @param     byte[]            file contents
+ *
+ * @return    definitionFile    the parsed file
+ *            error             error message, if any
+ */
+func parseDefinitionFile(name string, data []byte) (definitionFile, error) {
+
+	file := definitionFile{name, make(map[string]json.RawMessage)}
+
+	err := json.Unmarshal(data, &file.entries)
+	if err == nil {
+		return file, nil
+	}
+
+	// Point the designer at the line where the JSON went wrong.
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return file, &LoadError{File: name,
+			Line: 1 + bytes.Count(data[:syntaxErr.Offset], []byte("\n")),
+			Msg:  syntaxErr.Error()}
+	}
+
+	return file, &LoadError{File: name,
+		Msg: "expected an object of definitions keyed by name"}
+}
+
+// decodeEntry ... decode a single raw definition on top of v, so that
+// an override only needs to list the fields it changes.
+/*
+ * @param     string             file name, for error messages
+ * @param     string             key of the definition
+ * @param     json.RawMessage    raw definition
+ * @param     interface{}        pointer to the definition struct
+ *
+ * @return    error              error message, if any
+ */
+func decodeEntry(file string, key string, raw json.RawMessage,
+	v interface{}) error {
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	loadErr := &LoadError{File: file, Entry: key, Msg: err.Error()}
+
+	// Fields of the wrong type, e.g. a string where a number should be.
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		loadErr.Field = typeErr.Field
+		loadErr.Msg = fmt.Sprintf("expected a value of type %s, got %s",
+			typeErr.Type, typeErr.Value)
+		return loadErr
+	}
+
+	// Misspelled or unsupported fields.
+	if strings.HasPrefix(err.Error(), "json: unknown field ") {
+		loadErr.Field = strings.Trim(
+			strings.TrimPrefix(err.Error(), "json: unknown field "), "\"")
+		loadErr.Msg = "unknown field"
+	}
+
+	return loadErr
+}
+
+// sortedKeys ... keys of a set of definitions, in sorted order, so that
+// the same error is reported first every time.
+func sortedKeys(entries map[string]string) []string {
+
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// parseRune ... convert the string form of a character graphic to a rune.
+/*
+ * @param     string       field name, for error messages
+ * @param     string       string holding a single character
+ *
+ * @return    rune         the character
+ *            LoadError*   error, if the string is not a single character
+ */
+func parseRune(field string, s string) (rune, *LoadError) {
+
+	if utf8.RuneCountInString(s) != 1 {
+		return 0, fieldError(field, "must be exactly one character, got %q", s)
+	}
+
+	r, _ := utf8.DecodeRuneInString(s)
+
+	return r, nil
+}
@@ -0,0 +1,145 @@
+/*
+ * File: types/loader_test.go
+ *
+ * Description: Checks that bad definition files are reported with their
+ *              file, line, entry and field, and that a data directory
+ *              overrides the embedded definitions.
+ */
+
+package types
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeDataDir ... a data directory holding a single definition file.
+func writeDataDir(t *testing.T, name, contents string) (string, string) {
+
+	t.Helper()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir, path
+}
+
+func TestLoadErrors(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		file     string
+		contents string
+
+		// Where the error should point; the file is the one written.
+		line  int
+		entry string
+		field string
+	}{
+		{"syntax error", "items.json", "{\n" +
+			"    \"club\": {\n" +
+			"        \"name\": \"Club\",,\n" +
+			"    }\n" +
+			"}\n", 3, "", ""},
+		{"unknown field", "items.json",
+			`{"club": {"name": "Club", "category": "blunt", "ch": ")",
+			 "colour": "brown"}}`, 0, "club", "colour"},
+		{"wrong type", "items.json",
+			`{"club": {"name": "Club", "category": "blunt", "ch": ")",
+			 "weight": "heavy"}}`, 0, "club", "weight"},
+		{"missing required field", "items.json",
+			`{"club": {"name": "Club", "ch": ")"}}`, 0, "club", "category"},
+		{"bad value", "items.json",
+			`{"club": {"name": "Club", "category": "blunt", "ch": ")",
+			 "rarity": "legendary"}}`, 0, "club", "rarity"},
+		{"unknown loot", "creatures.json",
+			`{"dog": {"loot": [{"item": "club", "chance": 50}]}}`, 0, "dog",
+			"loot"},
+		{"unknown class", "creatures.json",
+			`{"dog": {"class": "99"}}`, 0, "dog", "class"},
+		{"level key", "levels.json",
+			`{"first": {"generator": "rooms"}}`, 0, "first", ""},
+	}
+
+	for _, tt := range tests {
+
+		dir, path := writeDataDir(t, tt.file, tt.contents)
+		err := loadAll(dir)
+
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) {
+			t.Errorf("%s: error = %v, want a LoadError", tt.name, err)
+			continue
+		}
+
+		if loadErr.File != path || loadErr.Line != tt.line ||
+			loadErr.Entry != tt.entry || loadErr.Field != tt.field ||
+			loadErr.Msg == "" {
+			t.Errorf("%s: error = %+v, want file %s, line %d, entry %q "+
+				"and field %q", tt.name, *loadErr, path, tt.line, tt.entry,
+				tt.field)
+		}
+	}
+}
+
+// loadAll ... load every kind of definition, in the order the game does.
+func loadAll(dataDir string) error {
+
+	classes := make(map[string]ClassTypeInfo)
+	items := make(map[string]ItemTypeInfo)
+
+	if err := LoadClassTypes(classes, dataDir); err != nil {
+		return err
+	}
+	if err := LoadItemTypes(items, dataDir); err != nil {
+		return err
+	}
+	err := LoadCreatureTypes(make(map[string]CreatureTypeInfo), classes,
+		items, dataDir)
+	if err != nil {
+		return err
+	}
+
+	return LoadLevelTypes(make(map[string]LevelTypeInfo), dataDir)
+}
+
+func TestLoadDefaults(t *testing.T) {
+
+	if err := loadAll(""); err != nil {
+		t.Fatalf("embedded definitions do not load: %v", err)
+	}
+}
+
+func TestDataDirOverrides(t *testing.T) {
+
+	dir, _ := writeDataDir(t, "items.json", `{
+		"sword": {"name": "Rusty Sword", "attack_increase": 1},
+		"club": {"name": "Club", "category": "blunt", "ch": ")"}
+	}`)
+
+	items := make(map[string]ItemTypeInfo)
+	if err := LoadItemTypes(items, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the fields given are overridden; the rest are kept.
+	sword := items["sword"]
+	if sword.Name != "Rusty Sword" || sword.Attack_increase != 1 ||
+		sword.Category != "blade" || sword.Durability_maximum != 10 {
+		t.Errorf("sword = %+v, want the embedded sword renamed, with an "+
+			"attack increase of 1", sword)
+	}
+
+	// New entries are added alongside the embedded ones.
+	if _, exists := items["club"]; !exists {
+		t.Error("club from the data directory was not loaded")
+	}
+	if items["dagger"].Name != "Dagger" {
+		t.Errorf("dagger = %+v, want the embedded dagger", items["dagger"])
+	}
+}